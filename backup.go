@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"kasir-api/config"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"kasir-api/services"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// runExport handles `kasir-api export`, writing categories, products and
+// promotions as JSON.
+func runExport(cfg *config.Config, args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	file := flags.String("file", "", "write to this file instead of stdout")
+	flags.Parse(args)
+
+	db := openDB(cfg)
+	defer db.Close()
+
+	backup, err := services.NewBackupService(repositories.NewBackupRepository(db)).Export(context.Background())
+	if err != nil {
+		log.Fatal("Failed to export catalog:", err)
+	}
+
+	out := os.Stdout
+	if *file != "" {
+		out, err = os.Create(*file)
+		if err != nil {
+			log.Fatal("Failed to create file:", err)
+		}
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(backup); err != nil {
+		log.Fatal("Failed to write backup:", err)
+	}
+	if *file != "" {
+		if err := out.Close(); err != nil {
+			log.Fatal("Failed to write backup:", err)
+		}
+		fmt.Fprintf(os.Stderr, "exported %d categories, %d products and %d promotions to %s\n",
+			len(backup.Categories), len(backup.Products), len(backup.Promotions), *file)
+	}
+}
+
+// runImport handles `kasir-api import`, restoring a file written by export.
+// The import is recorded in the audit log.
+func runImport(cfg *config.Config, args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	file := flags.String("file", "", "backup file written by export")
+	stock := flags.Bool("stock", false, "also overwrite the stock of existing products")
+	flags.Parse(args)
+
+	if *file == "" {
+		log.Fatal("--file is required")
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatal("Failed to open file:", err)
+	}
+	defer f.Close()
+
+	var backup models.CatalogBackup
+	if err := json.NewDecoder(f).Decode(&backup); err != nil {
+		log.Fatal("Failed to read backup:", err)
+	}
+
+	db := openDB(cfg)
+	defer db.Close()
+
+	ctx := context.Background()
+	result, err := services.NewBackupService(repositories.NewBackupRepository(db)).Import(ctx, &backup, *stock)
+	if errors.Is(err, models.ErrUnsupportedBackup) {
+		log.Fatalf("Backup version %d is not supported, expected %d", backup.Version, models.CatalogBackupVersion)
+	}
+	var invalid *models.Error
+	if errors.As(err, &invalid) && errors.Is(invalid, models.ErrInvalidBackup) {
+		for _, problem := range invalid.Details.([]string) {
+			log.Println(problem)
+		}
+		log.Fatal("Backup not imported, nothing was written")
+	}
+	if err != nil {
+		log.Fatal("Failed to import catalog:", err)
+	}
+
+	audit := services.NewAuditService(repositories.NewAuditRepository(db), cfg.RequestTimeout)
+	entry := models.AuditEntry{
+		ActorType:  models.AuditActorSystem,
+		ActorName:  "kasir-api import",
+		Action:     models.AuditActionImport,
+		EntityType: models.AuditEntityCatalog,
+		EntityID:   filepath.Base(*file),
+	}
+	if err := audit.Record(ctx, entry, nil, map[string]interface{}{"result": result, "stock": *stock}); err != nil {
+		log.Printf("Failed to record audit log for the import: %v", err)
+	}
+	fmt.Printf("imported %d categories, %d products and %d promotions\n", result.Categories, result.Products, result.Promotions)
+}
@@ -0,0 +1,227 @@
+// Package barcode reads the EAN-13 labels printed in store by scales at the
+// produce and meat counters. Such labels start with a prefix from 20 to 29,
+// reserved by GS1 for in-store use, and carry the PLU of the product along
+// with its price, weight or count.
+package barcode
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Kind is what the value embedded in a label stands for.
+type Kind int
+
+const (
+	// Price labels carry the price of the package in rupiah.
+	Price Kind = iota + 1
+	// Weight labels carry the weight in grams; the product price is per
+	// kilogram.
+	Weight
+	// Count labels carry the number of units in the package.
+	Count
+)
+
+// ErrInvalidLabel is returned for a scale label whose check digit or price
+// check digit does not match or whose price, weight or count is zero.
+var ErrInvalidLabel = errors.New("barcode: invalid scale label")
+
+// Format is one label layout, written as a 13 character mask such as
+// "22IIIIIWWWWWC": the leading digits are the prefix, I marks the PLU, P, W
+// or N the embedded price, weight or count, V the GS1 price check digit of
+// a 4 or 5 digit value, X a digit to ignore and C the EAN-13 check digit.
+type Format struct {
+	Mask   string
+	Prefix string
+	Kind   Kind
+	plu    [2]int
+	value  [2]int
+	// check is the position of the price check digit, 0 for none.
+	check int
+}
+
+// ParseFormat parses a mask; see Format.
+func ParseFormat(mask string) (Format, error) {
+	f := Format{Mask: mask}
+	if len(mask) != 13 {
+		return f, fmt.Errorf("barcode format %q: must be 13 characters", mask)
+	}
+	if mask[12] != 'C' {
+		return f, fmt.Errorf("barcode format %q: must end in the check digit C", mask)
+	}
+	f.Prefix = strings.TrimRight(mask, "IPWNVXC")
+	if len(f.Prefix) < 2 || f.Prefix[0] != '2' || strings.Trim(f.Prefix, "0123456789") != "" {
+		return f, fmt.Errorf("barcode format %q: must start with a prefix from 20 to 29", mask)
+	}
+
+	kinds := map[byte]Kind{'P': Price, 'W': Weight, 'N': Count}
+	for i := len(f.Prefix); i < 12; i++ {
+		c := mask[i]
+		switch c {
+		case 'I':
+			if !extend(&f.plu, i) {
+				return f, fmt.Errorf("barcode format %q: the PLU digits must be adjacent", mask)
+			}
+		case 'P', 'W', 'N':
+			if f.Kind != 0 && f.Kind != kinds[c] {
+				return f, fmt.Errorf("barcode format %q: only one of P, W and N may be used", mask)
+			}
+			f.Kind = kinds[c]
+			if !extend(&f.value, i) {
+				return f, fmt.Errorf("barcode format %q: the value digits must be adjacent", mask)
+			}
+		case 'V':
+			if f.check != 0 {
+				return f, fmt.Errorf("barcode format %q: only one price check digit V may be used", mask)
+			}
+			f.check = i
+		case 'X':
+		default:
+			return f, fmt.Errorf("barcode format %q: unexpected %q", mask, c)
+		}
+	}
+	if f.plu[1] == 0 {
+		return f, fmt.Errorf("barcode format %q: no PLU digits", mask)
+	}
+	if f.Kind == 0 {
+		return f, fmt.Errorf("barcode format %q: no price, weight or count digits", mask)
+	}
+	if n := f.value[1] - f.value[0]; f.check != 0 && n != 4 && n != 5 {
+		return f, fmt.Errorf("barcode format %q: the price check digit V needs 4 or 5 value digits", mask)
+	}
+	return f, nil
+}
+
+// extend grows the digit range r, given as [start, end), by position i. It
+// reports false when i does not follow the range.
+func extend(r *[2]int, i int) bool {
+	switch {
+	case r[1] == 0:
+		r[0], r[1] = i, i+1
+	case r[1] == i:
+		r[1]++
+	default:
+		return false
+	}
+	return true
+}
+
+// ParseFormats parses a comma separated list of masks. An empty list
+// disables scale labels.
+func ParseFormats(list string) ([]Format, error) {
+	formats := make([]Format, 0)
+	for _, mask := range strings.Split(list, ",") {
+		mask = strings.TrimSpace(mask)
+		if mask == "" {
+			continue
+		}
+		f, err := ParseFormat(mask)
+		if err != nil {
+			return nil, err
+		}
+		formats = append(formats, f)
+	}
+	return formats, nil
+}
+
+// Label is what a scale label encodes.
+type Label struct {
+	PLU   int
+	Kind  Kind
+	Value int
+}
+
+// Parse reads code with the first format whose prefix it starts with. It
+// returns nil and no error when code is not a scale label.
+func Parse(formats []Format, code string) (*Label, error) {
+	if len(code) != 13 || strings.Trim(code, "0123456789") != "" {
+		return nil, nil
+	}
+	for _, f := range formats {
+		if !strings.HasPrefix(code, f.Prefix) {
+			continue
+		}
+		if CheckDigit(code[:12]) != code[12] {
+			return nil, ErrInvalidLabel
+		}
+		if f.check != 0 && PriceCheckDigit(code[f.value[0]:f.value[1]]) != code[f.check] {
+			return nil, ErrInvalidLabel
+		}
+		plu, _ := strconv.Atoi(code[f.plu[0]:f.plu[1]])
+		value, _ := strconv.Atoi(code[f.value[0]:f.value[1]])
+		if value == 0 {
+			return nil, ErrInvalidLabel
+		}
+		return &Label{PLU: plu, Kind: f.Kind, Value: value}, nil
+	}
+	return nil, nil
+}
+
+// CheckDigit computes the EAN-13 check digit of the first 12 digits.
+func CheckDigit(digits string) byte {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		d := int(digits[i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// Products of a digit with the weighting factors of the GS1 price check
+// digit: 2- is the tens digit of 2d subtracted from its units digit, 5+ the
+// tens digit of 5d added to its units digit and 5- the tens digit
+// subtracted; 3 is the units digit of 3d.
+var (
+	weight2Minus = [10]int{0, 2, 4, 6, 8, 9, 1, 3, 5, 7}
+	weight3      = [10]int{0, 3, 6, 9, 2, 5, 8, 1, 4, 7}
+	weight5Plus  = [10]int{0, 5, 1, 6, 2, 7, 3, 8, 4, 9}
+	weight5Minus = [10]int{0, 5, 9, 4, 8, 3, 7, 2, 6, 1}
+)
+
+// PriceCheckDigit computes the GS1 price check digit of a 4 or 5 digit
+// price, weight or count. A 4 digit value is weighted 2-, 2-, 3, 5- and
+// the check digit is the units digit of three times the sum. A 5 digit
+// value is weighted 5+, 2-, 5-, 5+, 2- and the check digit is the digit
+// whose 5- product, added to the sum, makes a multiple of ten.
+func PriceCheckDigit(digits string) byte {
+	d := func(i int) int { return int(digits[i] - '0') }
+	if len(digits) == 4 {
+		sum := weight2Minus[d(0)] + weight2Minus[d(1)] + weight3[d(2)] + weight5Minus[d(3)]
+		return byte('0' + sum*3%10)
+	}
+	sum := weight5Plus[d(0)] + weight2Minus[d(1)] + weight5Minus[d(2)] + weight5Plus[d(3)] + weight2Minus[d(4)]
+	want := (10 - sum%10) % 10
+	for c, p := range weight5Minus {
+		if p == want {
+			return byte('0' + c)
+		}
+	}
+	return '0'
+}
+
+// Quantity is how many units of the product one label stands for. Price
+// and weight labels are one package; count labels hold that many units.
+func (l *Label) Quantity() int {
+	if l.Kind == Count {
+		return l.Value
+	}
+	return 1
+}
+
+// UnitPrice is what one Quantity of the label costs, given the product
+// price: the price on a price label, the price per kilogram times the
+// weight for a weight label and the product price for a count label.
+func (l *Label) UnitPrice(price int) int {
+	switch l.Kind {
+	case Price:
+		return l.Value
+	case Weight:
+		return (price*l.Value + 500) / 1000
+	}
+	return price
+}
@@ -0,0 +1,161 @@
+package barcode
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		mask    string
+		kind    Kind
+		wantErr bool
+	}{
+		{mask: "20IIIIIPPPPPC", kind: Price},
+		{mask: "22IIIIIWWWWWC", kind: Weight},
+		{mask: "23IIIIINNNNNC", kind: Count},
+		{mask: "21IIIIIVPPPPC", kind: Price},
+		{mask: "24IIIIXWWWWWC", kind: Weight},
+		{mask: "20IIIIIPPPPP", wantErr: true},
+		{mask: "20IIIIIPPPPPPC", wantErr: true},
+		{mask: "20IIIIIPPPPPX", wantErr: true},
+		{mask: "10IIIIIPPPPPC", wantErr: true},
+		{mask: "2IIIIIIPPPPPC", wantErr: true},
+		{mask: "20IIPIIPPPPPC", wantErr: true},
+		{mask: "20IIIIIPPWWWC", wantErr: true},
+		{mask: "20IIIIIPPQPPC", wantErr: true},
+		{mask: "20PPPPPPPPPPC", wantErr: true},
+		{mask: "20IIIIIIIIIIC", wantErr: true},
+		{mask: "20IIIIVVPPPPC", wantErr: true},
+		{mask: "20IIIIIIVPPPC", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mask, func(t *testing.T) {
+			f, err := ParseFormat(tt.mask)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseFormat(%q) = nil error, want an error", tt.mask)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFormat(%q): %v", tt.mask, err)
+			}
+			if f.Kind != tt.kind {
+				t.Errorf("ParseFormat(%q).Kind = %v, want %v", tt.mask, f.Kind, tt.kind)
+			}
+		})
+	}
+}
+
+func TestParseFormats(t *testing.T) {
+	formats, err := ParseFormats(" 20IIIIIPPPPPC, ,22IIIIIWWWWWC ")
+	if err != nil {
+		t.Fatalf("ParseFormats: %v", err)
+	}
+	if len(formats) != 2 || formats[0].Prefix != "20" || formats[1].Prefix != "22" {
+		t.Errorf("ParseFormats = %+v, want the 20 and 22 formats", formats)
+	}
+	if _, err := ParseFormats("20IIIIIPPPPPC,bad"); err == nil {
+		t.Error("ParseFormats with a bad mask = nil error, want an error")
+	}
+}
+
+func TestCheckDigit(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   byte
+	}{
+		{digits: "400638133393", want: '1'},
+		{digits: "201234501500", want: '5'},
+		{digits: "220004201250", want: '6'},
+		{digits: "000000000000", want: '0'},
+	}
+	for _, tt := range tests {
+		if got := CheckDigit(tt.digits); got != tt.want {
+			t.Errorf("CheckDigit(%q) = %c, want %c", tt.digits, got, tt.want)
+		}
+	}
+}
+
+func TestPriceCheckDigit(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   byte
+	}{
+		{digits: "2875", want: '9'},
+		{digits: "1250", want: '3'},
+		{digits: "0000", want: '0'},
+		{digits: "14685", want: '6'},
+		{digits: "00000", want: '0'},
+	}
+	for _, tt := range tests {
+		if got := PriceCheckDigit(tt.digits); got != tt.want {
+			t.Errorf("PriceCheckDigit(%q) = %c, want %c", tt.digits, got, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	formats, err := ParseFormats("20IIIIIPPPPPC,21IIIIIVPPPPC,22IIIIIWWWWWC,23IIIIINNNNNC")
+	if err != nil {
+		t.Fatalf("ParseFormats: %v", err)
+	}
+	tests := []struct {
+		name    string
+		code    string
+		want    *Label
+		wantErr error
+	}{
+		{name: "price", code: "2012345015005", want: &Label{PLU: 12345, Kind: Price, Value: 1500}},
+		{name: "weight", code: "2200042012506", want: &Label{PLU: 42, Kind: Weight, Value: 1250}},
+		{name: "count", code: "2300007000064", want: &Label{PLU: 7, Kind: Count, Value: 6}},
+		{name: "price check digit", code: "2112345312507", want: &Label{PLU: 12345, Kind: Price, Value: 1250}},
+		{name: "wrong price check digit", code: "2112345412504", wantErr: ErrInvalidLabel},
+		{name: "wrong check digit", code: "2012345015000", wantErr: ErrInvalidLabel},
+		{name: "zero value", code: "2012345000001", wantErr: ErrInvalidLabel},
+		{name: "other prefix", code: "4006381333931"},
+		{name: "too short", code: "201234501500"},
+		{name: "not digits", code: "20123450150A5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(formats, tt.code)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse(%q) error = %v, want %v", tt.code, err, tt.wantErr)
+			}
+			switch {
+			case got == nil && tt.want == nil:
+			case got == nil || tt.want == nil || *got != *tt.want:
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLabelPricing(t *testing.T) {
+	tests := []struct {
+		name      string
+		label     Label
+		price     int
+		quantity  int
+		unitPrice int
+	}{
+		{name: "price", label: Label{Kind: Price, Value: 1500}, price: 9000, quantity: 1, unitPrice: 1500},
+		{name: "weight exact", label: Label{Kind: Weight, Value: 1250}, price: 40000, quantity: 1, unitPrice: 50000},
+		{name: "weight rounds down", label: Label{Kind: Weight, Value: 1250}, price: 12345, quantity: 1, unitPrice: 15431},
+		{name: "weight rounds half up", label: Label{Kind: Weight, Value: 333}, price: 1500, quantity: 1, unitPrice: 500},
+		{name: "weight under a rupiah", label: Label{Kind: Weight, Value: 1}, price: 499, quantity: 1, unitPrice: 0},
+		{name: "count", label: Label{Kind: Count, Value: 6}, price: 2500, quantity: 6, unitPrice: 2500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.label.Quantity(); got != tt.quantity {
+				t.Errorf("Quantity() = %d, want %d", got, tt.quantity)
+			}
+			if got := tt.label.UnitPrice(tt.price); got != tt.unitPrice {
+				t.Errorf("UnitPrice(%d) = %d, want %d", tt.price, got, tt.unitPrice)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+type Config struct {
+	Port   string `mapstructure:"PORT"`
+	DBConn string `mapstructure:"DB_CONN"`
+	// HTTP server timeouts. ShutdownTimeout is how long in-flight requests
+	// get to finish after SIGTERM before they are cut off.
+	ReadTimeout       time.Duration `mapstructure:"HTTP_READ_TIMEOUT"`
+	ReadHeaderTimeout time.Duration `mapstructure:"HTTP_READ_HEADER_TIMEOUT"`
+	WriteTimeout      time.Duration `mapstructure:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `mapstructure:"HTTP_IDLE_TIMEOUT"`
+	ShutdownTimeout   time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+	// Deadlines for the database work done by one request. A request whose
+	// client disconnects is cancelled earlier. Checkout and reports get
+	// their own limits; everything else uses RequestTimeout.
+	RequestTimeout  time.Duration `mapstructure:"REQUEST_TIMEOUT"`
+	CheckoutTimeout time.Duration `mapstructure:"CHECKOUT_TIMEOUT"`
+	ReportTimeout   time.Duration `mapstructure:"REPORT_TIMEOUT"`
+	// CheckoutRowLock locks product rows with SELECT ... FOR UPDATE during
+	// checkout. When disabled, stock is still guarded by a conditional UPDATE.
+	CheckoutRowLock bool `mapstructure:"CHECKOUT_ROW_LOCK"`
+	// CheckoutRequireShift refuses sales and cash refunds while no shift is
+	// open. When disabled they are recorded outside any shift.
+	CheckoutRequireShift bool `mapstructure:"CHECKOUT_REQUIRE_SHIFT"`
+	// IdempotencyKeyTTL is how long a checkout Idempotency-Key is kept.
+	// After that the key is forgotten and may be used for a new checkout.
+	IdempotencyKeyTTL time.Duration `mapstructure:"IDEMPOTENCY_KEY_TTL"`
+	// IdempotencyCleanupInterval is how often expired keys are deleted.
+	IdempotencyCleanupInterval time.Duration `mapstructure:"IDEMPOTENCY_CLEANUP_INTERVAL"`
+	// AutoMigrate applies pending schema migrations at startup. When
+	// disabled the server refuses to start until `migrate up` has been run.
+	AutoMigrate bool `mapstructure:"AUTO_MIGRATE"`
+	// TaxRate and ServiceChargeRate are percentages, e.g. 11 for PPN 11%.
+	// With TaxInclusive, product prices already contain the tax.
+	TaxRate           float64 `mapstructure:"TAX_RATE"`
+	TaxInclusive      bool    `mapstructure:"TAX_INCLUSIVE"`
+	ServiceChargeRate float64 `mapstructure:"SERVICE_CHARGE_RATE"`
+	// ScaleBarcodeFormats lists the layouts of the EAN-13 labels printed by
+	// the store's scales, comma separated, e.g.
+	// "20IIIIIPPPPPC,22IIIIIWWWWWC"; see barcode.Format. Empty disables them.
+	ScaleBarcodeFormats string `mapstructure:"SCALE_BARCODE_FORMATS"`
+
+	// Printed at the top and bottom of every receipt.
+	StoreName     string `mapstructure:"STORE_NAME"`
+	StoreAddress  string `mapstructure:"STORE_ADDRESS"`
+	StorePhone    string `mapstructure:"STORE_PHONE"`
+	ReceiptFooter string `mapstructure:"RECEIPT_FOOTER"`
+	// ReceiptPaperWidth is the default paper width in millimetres, 58 or 80.
+	ReceiptPaperWidth int `mapstructure:"RECEIPT_PAPER_WIDTH"`
+
+	// PrinterAddress is the default network printer, host:port. Empty
+	// disables auto print and requires an address on every print request.
+	PrinterAddress string `mapstructure:"PRINTER_ADDRESS"`
+	// PrinterAllowedAddresses are the other printers a print request may
+	// name, comma separated. The server connects to no other address.
+	PrinterAllowedAddresses []string      `mapstructure:"PRINTER_ALLOWED_ADDRESSES"`
+	PrinterAutoPrint        bool          `mapstructure:"PRINTER_AUTO_PRINT"`
+	PrinterMaxAttempts      int           `mapstructure:"PRINTER_MAX_ATTEMPTS"`
+	PrinterRetryInterval    time.Duration `mapstructure:"PRINTER_RETRY_INTERVAL"`
+	PrinterTimeout          time.Duration `mapstructure:"PRINTER_TIMEOUT"`
+
+	// JWTSecret signs access tokens. When empty a random secret is used, so
+	// tokens stop working on restart.
+	JWTSecret       string        `mapstructure:"JWT_SECRET"`
+	AccessTokenTTL  time.Duration `mapstructure:"ACCESS_TOKEN_TTL"`
+	RefreshTokenTTL time.Duration `mapstructure:"REFRESH_TOKEN_TTL"`
+	// OwnerUsername and OwnerPassword create the first owner account on a
+	// database without users.
+	OwnerUsername string `mapstructure:"OWNER_USERNAME"`
+	OwnerPassword string `mapstructure:"OWNER_PASSWORD"`
+}
+
+// splitList splits a comma separated setting, dropping empty items.
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func Load() *Config {
+	viper.AutomaticEnv()
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+
+	viper.SetDefault("HTTP_READ_TIMEOUT", "15s")
+	viper.SetDefault("HTTP_READ_HEADER_TIMEOUT", "5s")
+	viper.SetDefault("HTTP_WRITE_TIMEOUT", "30s")
+	viper.SetDefault("HTTP_IDLE_TIMEOUT", "60s")
+	viper.SetDefault("SHUTDOWN_TIMEOUT", "20s")
+	viper.SetDefault("REQUEST_TIMEOUT", "10s")
+	viper.SetDefault("CHECKOUT_TIMEOUT", "15s")
+	viper.SetDefault("REPORT_TIMEOUT", "25s")
+	viper.SetDefault("CHECKOUT_ROW_LOCK", true)
+	viper.SetDefault("CHECKOUT_REQUIRE_SHIFT", false)
+	viper.SetDefault("IDEMPOTENCY_KEY_TTL", "24h")
+	viper.SetDefault("IDEMPOTENCY_CLEANUP_INTERVAL", "1h")
+	viper.SetDefault("AUTO_MIGRATE", true)
+	viper.SetDefault("STORE_NAME", "Kasir")
+	viper.SetDefault("RECEIPT_FOOTER", "Terima kasih")
+	viper.SetDefault("RECEIPT_PAPER_WIDTH", 58)
+	viper.SetDefault("PRINTER_MAX_ATTEMPTS", 5)
+	viper.SetDefault("PRINTER_RETRY_INTERVAL", "5s")
+	viper.SetDefault("PRINTER_TIMEOUT", "5s")
+	viper.SetDefault("ACCESS_TOKEN_TTL", "15m")
+	viper.SetDefault("REFRESH_TOKEN_TTL", "720h")
+
+	if _, err := os.Stat(".env"); err == nil {
+		viper.SetConfigFile(".env")
+		_ = viper.ReadInConfig()
+	}
+
+	return &Config{
+		Port:   viper.GetString("PORT"),
+		DBConn: viper.GetString("DB_CONN"),
+
+		ReadTimeout:       viper.GetDuration("HTTP_READ_TIMEOUT"),
+		ReadHeaderTimeout: viper.GetDuration("HTTP_READ_HEADER_TIMEOUT"),
+		WriteTimeout:      viper.GetDuration("HTTP_WRITE_TIMEOUT"),
+		IdleTimeout:       viper.GetDuration("HTTP_IDLE_TIMEOUT"),
+		ShutdownTimeout:   viper.GetDuration("SHUTDOWN_TIMEOUT"),
+		RequestTimeout:    viper.GetDuration("REQUEST_TIMEOUT"),
+		CheckoutTimeout:   viper.GetDuration("CHECKOUT_TIMEOUT"),
+		ReportTimeout:     viper.GetDuration("REPORT_TIMEOUT"),
+
+		CheckoutRowLock:            viper.GetBool("CHECKOUT_ROW_LOCK"),
+		CheckoutRequireShift:       viper.GetBool("CHECKOUT_REQUIRE_SHIFT"),
+		IdempotencyKeyTTL:          viper.GetDuration("IDEMPOTENCY_KEY_TTL"),
+		IdempotencyCleanupInterval: viper.GetDuration("IDEMPOTENCY_CLEANUP_INTERVAL"),
+		AutoMigrate:                viper.GetBool("AUTO_MIGRATE"),
+
+		TaxRate:           viper.GetFloat64("TAX_RATE"),
+		TaxInclusive:      viper.GetBool("TAX_INCLUSIVE"),
+		ServiceChargeRate: viper.GetFloat64("SERVICE_CHARGE_RATE"),
+
+		ScaleBarcodeFormats: viper.GetString("SCALE_BARCODE_FORMATS"),
+
+		StoreName:         viper.GetString("STORE_NAME"),
+		StoreAddress:      viper.GetString("STORE_ADDRESS"),
+		StorePhone:        viper.GetString("STORE_PHONE"),
+		ReceiptFooter:     viper.GetString("RECEIPT_FOOTER"),
+		ReceiptPaperWidth: viper.GetInt("RECEIPT_PAPER_WIDTH"),
+
+		PrinterAddress:          viper.GetString("PRINTER_ADDRESS"),
+		PrinterAllowedAddresses: splitList(viper.GetString("PRINTER_ALLOWED_ADDRESSES")),
+		PrinterAutoPrint:        viper.GetBool("PRINTER_AUTO_PRINT"),
+		PrinterMaxAttempts:      viper.GetInt("PRINTER_MAX_ATTEMPTS"),
+		PrinterRetryInterval:    viper.GetDuration("PRINTER_RETRY_INTERVAL"),
+		PrinterTimeout:          viper.GetDuration("PRINTER_TIMEOUT"),
+
+		JWTSecret:       viper.GetString("JWT_SECRET"),
+		AccessTokenTTL:  viper.GetDuration("ACCESS_TOKEN_TTL"),
+		RefreshTokenTTL: viper.GetDuration("REFRESH_TOKEN_TTL"),
+		OwnerUsername:   viper.GetString("OWNER_USERNAME"),
+		OwnerPassword:   viper.GetString("OWNER_PASSWORD"),
+	}
+}
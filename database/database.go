@@ -7,7 +7,8 @@ import (
 	_ "github.com/jackc/pgx/v5/stdlib"
 )
 
-func InitDB(connectionString string) (*sql.DB, error) {
+// Open connects to the database without touching the schema.
+func Open(connectionString string) (*sql.DB, error) {
 	db, err := sql.Open("pgx", connectionString)
 	if err != nil {
 		return nil, err
@@ -15,6 +16,7 @@ func InitDB(connectionString string) (*sql.DB, error) {
 
 	err = db.Ping()
 	if err != nil {
+		db.Close()
 		return nil, err
 	}
 
@@ -24,3 +26,31 @@ func InitDB(connectionString string) (*sql.DB, error) {
 	log.Println("Database connected successfully")
 	return db, nil
 }
+
+// InitDB connects to the database and brings the schema up to date. With
+// autoMigrate disabled the schema is only checked, and a database with
+// pending migrations is an error.
+func InitDB(connectionString string, autoMigrate bool) (*sql.DB, error) {
+	db, err := Open(connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	if !autoMigrate {
+		if err := verifySchema(db); err != nil {
+			db.Close()
+			return nil, err
+		}
+		return db, nil
+	}
+
+	applied, err := MigrateUp(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	for _, m := range applied {
+		log.Printf("Applied migration %04d_%s", m.Version, m.Name)
+	}
+	return db, nil
+}
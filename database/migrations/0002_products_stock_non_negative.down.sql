@@ -0,0 +1,2 @@
+ALTER TABLE products
+    DROP CONSTRAINT IF EXISTS products_stock_non_negative;
@@ -0,0 +1,6 @@
+-- Overselling before row locking could leave stock below zero. The check is
+-- added NOT VALID so that such rows do not stop the upgrade; every new write
+-- is checked. 0021_reconcile_negative_stock sets them to zero, recording the
+-- old figure in the audit log, and validates the constraint.
+ALTER TABLE products
+    ADD CONSTRAINT products_stock_non_negative CHECK (stock >= 0) NOT VALID;
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key            VARCHAR(255) PRIMARY KEY,
+    request_hash   CHAR(64) NOT NULL,
+    transaction_id INT REFERENCES transactions(id),
+    response       JSONB,
+    created_at     TIMESTAMP NOT NULL DEFAULT NOW()
+);
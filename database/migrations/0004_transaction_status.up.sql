@@ -0,0 +1,10 @@
+ALTER TABLE transactions
+    ADD COLUMN status        VARCHAR(20) NOT NULL DEFAULT 'completed',
+    ADD COLUMN cancelled_at  TIMESTAMP,
+    ADD COLUMN cancelled_by  VARCHAR(255),
+    ADD COLUMN cancel_reason TEXT;
+
+ALTER TABLE transactions
+    ADD CONSTRAINT transactions_status_check CHECK (status IN ('completed', 'voided', 'refunded'));
+
+CREATE INDEX IF NOT EXISTS transactions_created_at_idx ON transactions (created_at);
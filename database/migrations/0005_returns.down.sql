@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS return_details;
+DROP TABLE IF EXISTS returns;
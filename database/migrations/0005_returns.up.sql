@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS returns (
+    id             SERIAL PRIMARY KEY,
+    transaction_id INT NOT NULL REFERENCES transactions(id),
+    total_amount   INT NOT NULL,
+    reason         TEXT NOT NULL,
+    performed_by   VARCHAR(255) NOT NULL,
+    created_at     TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS returns_transaction_id_idx ON returns (transaction_id);
+CREATE INDEX IF NOT EXISTS returns_created_at_idx ON returns (created_at);
+
+CREATE TABLE IF NOT EXISTS return_details (
+    id                    SERIAL PRIMARY KEY,
+    return_id             INT NOT NULL REFERENCES returns(id) ON DELETE CASCADE,
+    transaction_detail_id INT NOT NULL REFERENCES transaction_details(id),
+    product_id            INT NOT NULL REFERENCES products(id),
+    quantity              INT NOT NULL CHECK (quantity > 0),
+    amount                INT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS return_details_transaction_detail_id_idx ON return_details (transaction_detail_id);
@@ -0,0 +1,15 @@
+ALTER TABLE transactions
+    ADD COLUMN paid_amount   INT NOT NULL DEFAULT 0,
+    ADD COLUMN change_amount INT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS transaction_payments (
+    id             SERIAL PRIMARY KEY,
+    transaction_id INT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    method         VARCHAR(20) NOT NULL CHECK (method IN ('cash', 'debit_card', 'qris', 'ewallet', 'transfer')),
+    amount         INT NOT NULL,
+    tendered       INT NOT NULL,
+    reference      VARCHAR(255) NOT NULL DEFAULT '',
+    created_at     TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS transaction_payments_transaction_id_idx ON transaction_payments (transaction_id);
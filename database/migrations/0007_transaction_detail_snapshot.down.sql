@@ -0,0 +1,19 @@
+ALTER TABLE return_details
+    DROP CONSTRAINT IF EXISTS return_details_product_id_fkey,
+    ADD CONSTRAINT return_details_product_id_fkey
+        FOREIGN KEY (product_id) REFERENCES products(id);
+
+ALTER TABLE transaction_details
+    DROP CONSTRAINT IF EXISTS transaction_details_product_id_fkey,
+    ADD CONSTRAINT transaction_details_product_id_fkey
+        FOREIGN KEY (product_id) REFERENCES products(id);
+
+ALTER TABLE transaction_details
+    DROP COLUMN IF EXISTS unit_cost,
+    DROP COLUMN IF EXISTS unit_price,
+    DROP COLUMN IF EXISTS category_name,
+    DROP COLUMN IF EXISTS category_id,
+    DROP COLUMN IF EXISTS product_name;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS cost;
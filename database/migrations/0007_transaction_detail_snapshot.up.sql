@@ -0,0 +1,33 @@
+ALTER TABLE products
+    ADD COLUMN cost INT NOT NULL DEFAULT 0;
+
+ALTER TABLE transaction_details
+    ADD COLUMN product_name  VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN category_id   INT,
+    ADD COLUMN category_name VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN unit_price    INT NOT NULL DEFAULT 0,
+    ADD COLUMN unit_cost     INT NOT NULL DEFAULT 0;
+
+-- Best effort backfill for sales made before snapshots existed; the product
+-- may have been repriced since, so the price is derived from the subtotal.
+UPDATE transaction_details td
+SET product_name  = p.name,
+    category_id   = p.category_id,
+    category_name = COALESCE(c.name, ''),
+    unit_price    = td.subtotal / NULLIF(td.quantity, 0)
+FROM products p
+LEFT JOIN categories c ON c.id = p.category_id
+WHERE p.id = td.product_id;
+
+-- Products can now be deleted without breaking the receipts that sold them.
+ALTER TABLE transaction_details
+    ALTER COLUMN product_id DROP NOT NULL,
+    DROP CONSTRAINT IF EXISTS transaction_details_product_id_fkey,
+    ADD CONSTRAINT transaction_details_product_id_fkey
+        FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE SET NULL;
+
+ALTER TABLE return_details
+    ALTER COLUMN product_id DROP NOT NULL,
+    DROP CONSTRAINT IF EXISTS return_details_product_id_fkey,
+    ADD CONSTRAINT return_details_product_id_fkey
+        FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE SET NULL;
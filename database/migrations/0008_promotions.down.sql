@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS transaction_promotions;
+
+ALTER TABLE transaction_details
+    DROP COLUMN IF EXISTS discount;
+
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS discount_amount,
+    DROP COLUMN IF EXISTS gross_amount;
+
+DROP TABLE IF EXISTS promotions;
@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS promotions (
+    id           SERIAL PRIMARY KEY,
+    name         VARCHAR(255) NOT NULL,
+    type         VARCHAR(20) NOT NULL CHECK (type IN ('percentage', 'fixed', 'buy_x_get_y')),
+    scope        VARCHAR(20) NOT NULL CHECK (scope IN ('product', 'category', 'cart')),
+    product_id   INT REFERENCES products(id) ON DELETE CASCADE,
+    category_id  INT REFERENCES categories(id) ON DELETE CASCADE,
+    value        INT NOT NULL DEFAULT 0,
+    buy_qty      INT NOT NULL DEFAULT 0,
+    get_qty      INT NOT NULL DEFAULT 0,
+    min_spend    INT NOT NULL DEFAULT 0,
+    max_discount INT NOT NULL DEFAULT 0,
+    starts_at    TIMESTAMP,
+    ends_at      TIMESTAMP,
+    priority     INT NOT NULL DEFAULT 0,
+    stackable    BOOLEAN NOT NULL DEFAULT FALSE,
+    active       BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at   TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE transactions
+    ADD COLUMN gross_amount    INT NOT NULL DEFAULT 0,
+    ADD COLUMN discount_amount INT NOT NULL DEFAULT 0;
+
+UPDATE transactions SET gross_amount = total_amount;
+
+ALTER TABLE transaction_details
+    ADD COLUMN discount INT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS transaction_promotions (
+    id             SERIAL PRIMARY KEY,
+    transaction_id INT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    promotion_id   INT REFERENCES promotions(id) ON DELETE SET NULL,
+    promotion_name VARCHAR(255) NOT NULL,
+    amount         INT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS transaction_promotions_transaction_id_idx ON transaction_promotions (transaction_id);
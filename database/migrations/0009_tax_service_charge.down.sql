@@ -0,0 +1,20 @@
+ALTER TABLE return_details
+    DROP COLUMN IF EXISTS tax_amount;
+
+ALTER TABLE transaction_details
+    DROP COLUMN IF EXISTS total,
+    DROP COLUMN IF EXISTS tax_amount,
+    DROP COLUMN IF EXISTS tax_base,
+    DROP COLUMN IF EXISTS tax_rate,
+    DROP COLUMN IF EXISTS service_charge;
+
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS tax_inclusive,
+    DROP COLUMN IF EXISTS tax_amount,
+    DROP COLUMN IF EXISTS service_charge;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS tax_exempt;
+
+ALTER TABLE categories
+    DROP COLUMN IF EXISTS tax_exempt;
@@ -0,0 +1,24 @@
+ALTER TABLE categories
+    ADD COLUMN tax_exempt BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE products
+    ADD COLUMN tax_exempt BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE transactions
+    ADD COLUMN service_charge INT NOT NULL DEFAULT 0,
+    ADD COLUMN tax_amount     INT NOT NULL DEFAULT 0,
+    ADD COLUMN tax_inclusive  BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- tax_rate is in basis points (1100 = 11%); tax_base is the DPP and total is
+-- what the customer paid for the line.
+ALTER TABLE transaction_details
+    ADD COLUMN service_charge INT NOT NULL DEFAULT 0,
+    ADD COLUMN tax_rate       INT NOT NULL DEFAULT 0,
+    ADD COLUMN tax_base       INT NOT NULL DEFAULT 0,
+    ADD COLUMN tax_amount     INT NOT NULL DEFAULT 0,
+    ADD COLUMN total          INT NOT NULL DEFAULT 0;
+
+UPDATE transaction_details SET tax_base = subtotal, total = subtotal;
+
+ALTER TABLE return_details
+    ADD COLUMN tax_amount INT NOT NULL DEFAULT 0;
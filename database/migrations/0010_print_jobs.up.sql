@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS print_jobs (
+    id              SERIAL PRIMARY KEY,
+    transaction_id  INT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    printer_address VARCHAR(255) NOT NULL,
+    paper_width     INT NOT NULL DEFAULT 58,
+    status          VARCHAR(20) NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'printing', 'done', 'failed')),
+    attempts        INT NOT NULL DEFAULT 0,
+    last_error      TEXT NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    printed_at      TIMESTAMP,
+    created_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS print_jobs_due_idx ON print_jobs (next_attempt_at) WHERE status = 'queued';
+CREATE INDEX IF NOT EXISTS print_jobs_transaction_id_idx ON print_jobs (transaction_id);
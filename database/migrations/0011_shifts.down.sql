@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS transactions_shift_id_idx;
+
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS shift_id;
+
+DROP TABLE IF EXISTS shift_cash_movements;
+DROP TABLE IF EXISTS shifts;
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS shifts (
+    id           SERIAL PRIMARY KEY,
+    cashier_name VARCHAR(255) NOT NULL,
+    status       VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed')),
+    opening_cash INT NOT NULL,
+    expected_cash INT,
+    counted_cash INT,
+    close_note   TEXT NOT NULL DEFAULT '',
+    opened_at    TIMESTAMP NOT NULL DEFAULT NOW(),
+    closed_at    TIMESTAMP
+);
+
+-- A cashier can only have one shift open at a time.
+CREATE UNIQUE INDEX IF NOT EXISTS shifts_open_cashier_idx ON shifts (cashier_name) WHERE status = 'open';
+
+CREATE TABLE IF NOT EXISTS shift_cash_movements (
+    id         SERIAL PRIMARY KEY,
+    shift_id   INT NOT NULL REFERENCES shifts(id) ON DELETE CASCADE,
+    type       VARCHAR(10) NOT NULL CHECK (type IN ('pay_in', 'pay_out')),
+    amount     INT NOT NULL CHECK (amount > 0),
+    reason     TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS shift_cash_movements_shift_id_idx ON shift_cash_movements (shift_id);
+
+ALTER TABLE transactions
+    ADD COLUMN shift_id INT REFERENCES shifts(id);
+
+CREATE INDEX IF NOT EXISTS transactions_shift_id_idx ON transactions (shift_id);
@@ -0,0 +1,5 @@
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS terminal_id;
+
+DROP TABLE IF EXISTS terminal_api_keys;
+DROP TABLE IF EXISTS terminals;
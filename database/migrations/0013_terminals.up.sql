@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS terminals (
+    id          SERIAL PRIMARY KEY,
+    name        VARCHAR(100) NOT NULL,
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    active      BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Keys are random; only their SHA-256 and a short prefix are stored.
+CREATE TABLE IF NOT EXISTS terminal_api_keys (
+    id           SERIAL PRIMARY KEY,
+    terminal_id  INT NOT NULL REFERENCES terminals(id) ON DELETE CASCADE,
+    prefix       VARCHAR(16) NOT NULL,
+    key_hash     CHAR(64) NOT NULL UNIQUE,
+    created_at   TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMP,
+    revoked_at   TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS terminal_api_keys_terminal_id_idx ON terminal_api_keys (terminal_id);
+
+ALTER TABLE transactions
+    ADD COLUMN terminal_id INT REFERENCES terminals(id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS products_category_id_idx;
+DROP INDEX IF EXISTS products_created_at_idx;
+DROP INDEX IF EXISTS products_stock_idx;
+DROP INDEX IF EXISTS products_price_idx;
+DROP INDEX IF EXISTS products_name_idx;
@@ -0,0 +1,6 @@
+-- Each sort of the product list pages on (column, id).
+CREATE INDEX IF NOT EXISTS products_name_idx ON products (name, id);
+CREATE INDEX IF NOT EXISTS products_price_idx ON products (price, id);
+CREATE INDEX IF NOT EXISTS products_stock_idx ON products (stock, id);
+CREATE INDEX IF NOT EXISTS products_created_at_idx ON products (created_at, id);
+CREATE INDEX IF NOT EXISTS products_category_id_idx ON products (category_id);
@@ -0,0 +1,10 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS sku VARCHAR(64);
+CREATE UNIQUE INDEX IF NOT EXISTS products_sku_key ON products (sku);
+
+-- A product may carry several barcodes, such as the manufacturer's and the
+-- store's own label. The primary key is the index the scan lookup hits.
+CREATE TABLE IF NOT EXISTS product_barcodes (
+    barcode    VARCHAR(64) PRIMARY KEY,
+    product_id INT NOT NULL REFERENCES products(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS product_barcodes_product_id_idx ON product_barcodes (product_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS products_plu_key;
+ALTER TABLE products DROP COLUMN IF EXISTS plu;
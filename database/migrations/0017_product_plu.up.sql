@@ -0,0 +1,3 @@
+-- The PLU links a product to the labels printed by the scales.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS plu INT;
+CREATE UNIQUE INDEX IF NOT EXISTS products_plu_key ON products (plu);
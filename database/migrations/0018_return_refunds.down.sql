@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS returns_shift_id_idx;
+ALTER TABLE returns
+    DROP COLUMN IF EXISTS shift_id,
+    DROP COLUMN IF EXISTS refund_method;
@@ -0,0 +1,7 @@
+-- Returns made before this migration are taken as cash refunds outside any
+-- shift: which drawer they were paid from is not known.
+ALTER TABLE returns
+    ADD COLUMN IF NOT EXISTS refund_method VARCHAR(20) NOT NULL DEFAULT 'cash',
+    ADD COLUMN IF NOT EXISTS shift_id      INT REFERENCES shifts(id);
+
+CREATE INDEX IF NOT EXISTS returns_shift_id_idx ON returns (shift_id);
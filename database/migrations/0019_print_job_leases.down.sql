@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS print_jobs_lease_idx;
+ALTER TABLE print_jobs DROP COLUMN IF EXISTS lease_expires_at;
@@ -0,0 +1,7 @@
+-- A claimed job is leased to its worker until lease_expires_at; only then
+-- may another worker take it back. Jobs already printing get an expired
+-- lease, as before this migration they were requeued at startup.
+ALTER TABLE print_jobs ADD COLUMN IF NOT EXISTS lease_expires_at TIMESTAMP;
+UPDATE print_jobs SET lease_expires_at = NOW() WHERE status = 'printing';
+
+CREATE INDEX IF NOT EXISTS print_jobs_lease_idx ON print_jobs (lease_expires_at) WHERE status = 'printing';
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idempotency_keys_created_at_idx;
+-- Only one scope's key can survive under the old global primary key.
+DELETE FROM idempotency_keys a USING idempotency_keys b
+WHERE a.key = b.key AND a.created_at < b.created_at;
+DELETE FROM idempotency_keys a USING idempotency_keys b
+WHERE a.key = b.key AND a.created_at = b.created_at AND a.scope < b.scope;
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD CONSTRAINT idempotency_keys_pkey PRIMARY KEY (key);
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS scope;
@@ -0,0 +1,9 @@
+-- Keys are unique per caller rather than across the store, so two terminals
+-- picking the same key no longer see each other's checkouts. Keys stored
+-- before this migration keep an empty scope, which no caller uses, and are
+-- removed once the retention window has passed.
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS scope VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD CONSTRAINT idempotency_keys_pkey PRIMARY KEY (scope, key);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_created_at_idx ON idempotency_keys (created_at);
@@ -0,0 +1,2 @@
+-- The stock set to zero is not restored; the audit log has the old figures.
+SELECT 1;
@@ -0,0 +1,13 @@
+-- Stock left below zero by overselling is set to zero so that the
+-- non-negative check from 0002 can be validated. The figure each product had
+-- is kept in the audit log, to be reconciled with the next stock count.
+INSERT INTO audit_logs (actor_type, actor_name, action, entity_type, entity_id, before, after)
+SELECT 'system', 'migration 0021', 'update', 'product', id::text,
+       jsonb_build_object('stock', stock), jsonb_build_object('stock', 0)
+FROM products
+WHERE stock < 0
+ORDER BY id;
+
+UPDATE products SET stock = 0 WHERE stock < 0;
+
+ALTER TABLE products VALIDATE CONSTRAINT products_stock_non_negative;
@@ -0,0 +1,3 @@
+DELETE FROM returns WHERE full_refund;
+ALTER TABLE returns
+    DROP COLUMN IF EXISTS full_refund;
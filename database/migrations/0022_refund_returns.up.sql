@@ -0,0 +1,35 @@
+-- A refund of a whole sale is recorded as a return of everything not yet
+-- returned, marked full_refund so that reports can show refunds apart from
+-- partial returns.
+ALTER TABLE returns
+    ADD COLUMN IF NOT EXISTS full_refund BOOLEAN NOT NULL DEFAULT false;
+
+-- Refunds made before this migration get their return now. Like the returns
+-- from before 0018 they are taken as cash paid outside any shift, since the
+-- drawer they came from is not known.
+WITH remaining AS (
+    SELECT td.transaction_id,
+           td.id AS transaction_detail_id,
+           td.product_id,
+           td.quantity - COALESCE(SUM(rd.quantity), 0) AS quantity,
+           td.total - COALESCE(SUM(rd.amount), 0) AS amount,
+           td.tax_amount - COALESCE(SUM(rd.tax_amount), 0) AS tax_amount
+    FROM transaction_details td
+    JOIN transactions t ON t.id = td.transaction_id
+    LEFT JOIN return_details rd ON rd.transaction_detail_id = td.id
+    WHERE t.status = 'refunded'
+    GROUP BY td.id
+    HAVING td.quantity - COALESCE(SUM(rd.quantity), 0) > 0
+), refunds AS (
+    INSERT INTO returns (transaction_id, total_amount, refund_method, reason, performed_by, created_at, full_refund)
+    SELECT t.id, SUM(r.amount), 'cash', COALESCE(t.cancel_reason, ''), COALESCE(t.cancelled_by, ''),
+           COALESCE(t.cancelled_at, t.created_at), true
+    FROM transactions t
+    JOIN remaining r ON r.transaction_id = t.id
+    GROUP BY t.id
+    RETURNING id, transaction_id
+)
+INSERT INTO return_details (return_id, transaction_detail_id, product_id, quantity, amount, tax_amount)
+SELECT f.id, r.transaction_detail_id, r.product_id, r.quantity, r.amount, r.tax_amount
+FROM refunds f
+JOIN remaining r ON r.transaction_id = f.transaction_id;
@@ -0,0 +1,2 @@
+ALTER TABLE shifts
+    DROP COLUMN IF EXISTS closing_summary;
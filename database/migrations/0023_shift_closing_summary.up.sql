@@ -0,0 +1,4 @@
+-- The Z report as it stood when the shift was closed. Shifts closed before
+-- this migration have none and are summarized from their sales as before.
+ALTER TABLE shifts
+    ADD COLUMN IF NOT EXISTS closing_summary JSONB;
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"kasir-api/middleware"
+	"kasir-api/models"
+	"kasir-api/services"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	service *services.AuditService
+}
+
+func NewAuditHandler(service *services.AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+// recordAudit logs a change made by the current request. It runs after the
+// change has been saved, so a failure is logged rather than failing the
+// request.
+func recordAudit(c *gin.Context, audit *services.AuditService, action, entityType string, entityID int, before, after interface{}) {
+	entry := models.AuditEntry{
+		ActorType:  models.AuditActorSystem,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   strconv.Itoa(entityID),
+		RequestID:  middleware.GetRequestID(c),
+	}
+	if p := middleware.CurrentPrincipal(c); p != nil {
+		entry.ActorName = p.Username
+		if p.TerminalID != 0 {
+			entry.ActorType = models.AuditActorTerminal
+			entry.ActorID = &p.TerminalID
+		} else {
+			entry.ActorType = models.AuditActorUser
+			entry.ActorID = &p.UserID
+		}
+	}
+
+	if err := audit.Record(c.Request.Context(), entry, before, after); err != nil {
+		log.Printf("Failed to record audit log for %s %s %d: %v", action, entityType, entityID, err)
+	}
+}
+
+// actorName is the name recorded as having done something in the current
+// request: the username of the caller, or for a terminal its name behind
+// middleware.TerminalNamePrefix. Names sent in the body are never trusted
+// for this.
+func actorName(c *gin.Context) string {
+	if p := middleware.CurrentPrincipal(c); p != nil {
+		return p.Username
+	}
+	return ""
+}
+
+func (h *AuditHandler) GetAll(c *gin.Context) {
+	filter := models.AuditFilter{
+		ActorType:  c.Query("actor_type"),
+		Action:     c.Query("action"),
+		EntityType: c.Query("entity_type"),
+		EntityID:   c.Query("entity_id"),
+		RequestID:  c.Query("request_id"),
+		StartDate:  c.Query("start_date"),
+		EndDate:    c.Query("end_date"),
+	}
+
+	switch filter.ActorType {
+	case "", models.AuditActorUser, models.AuditActorTerminal, models.AuditActorSystem:
+	default:
+		c.Error(models.NewInvalidFieldError("actor_type", "oneof", "user terminal system"))
+		return
+	}
+
+	intParams := []struct {
+		name   string
+		target *int
+	}{
+		{"page", &filter.Page},
+		{"limit", &filter.Limit},
+		{"actor_id", &filter.ActorID},
+	}
+	for _, p := range intParams {
+		if v := c.Query(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				c.Error(models.NewInvalidFieldError(p.name, "number", ""))
+				return
+			}
+			*p.target = n
+		}
+	}
+
+	entries, pagination, err := h.service.GetAll(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       entries,
+		"pagination": pagination,
+	})
+}
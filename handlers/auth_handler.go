@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"kasir-api/middleware"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthHandler struct {
+	service     *services.AuthService
+	userService *services.UserService
+}
+
+func NewAuthHandler(service *services.AuthService, userService *services.UserService) *AuthHandler {
+	return &AuthHandler{service: service, userService: userService}
+}
+
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req models.LoginRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	tokens, err := h.service.Login(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req models.RefreshRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	tokens, err := h.service.Refresh(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req models.RefreshRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.service.Logout(c.Request.Context(), req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "logged_out"),
+	})
+}
+
+// Me returns the account of the logged in user. Terminals have no account.
+func (h *AuthHandler) Me(c *gin.Context) {
+	p := middleware.CurrentPrincipal(c)
+	if p == nil || p.UserID == 0 {
+		c.Error(models.ErrUserLoginRequired)
+		return
+	}
+
+	user, err := h.userService.GetByID(c.Request.Context(), p.UserID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
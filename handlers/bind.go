@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bindJSON decodes the request body into req and validates it. On failure
+// it records the error on c and returns false; the handler should return.
+func bindJSON(c *gin.Context, req interface{}) bool {
+	return decodeJSON(c, req) && validateRequest(c, req)
+}
+
+// decodeJSON decodes the request body into req, for handlers that fill in
+// defaults before validating.
+func decodeJSON(c *gin.Context, req interface{}) bool {
+	err := json.NewDecoder(c.Request.Body).Decode(req)
+	if err == nil {
+		return true
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		c.Error(models.NewInvalidFieldError(typeErr.Field, "type", typeErr.Type.String()))
+		return false
+	}
+	c.Error(models.ErrInvalidBody)
+	return false
+}
+
+// validateRequest checks req against its validate tags.
+func validateRequest(c *gin.Context, req interface{}) bool {
+	if err := validation.Struct(req); err != nil {
+		c.Error(err)
+		return false
+	}
+	return true
+}
@@ -1,8 +1,6 @@
 package handlers
 
 import (
-	"database/sql"
-	"encoding/json"
 	"kasir-api/models"
 	"kasir-api/services"
 	"net/http"
@@ -13,18 +11,17 @@ import (
 
 type CategoryHandler struct {
 	service *services.CategoryService
+	audit   *services.AuditService
 }
 
-func NewCategoryHandler(service *services.CategoryService) *CategoryHandler {
-	return &CategoryHandler{service: service}
+func NewCategoryHandler(service *services.CategoryService, audit *services.AuditService) *CategoryHandler {
+	return &CategoryHandler{service: service, audit: audit}
 }
 
 func (h *CategoryHandler) GetAll(c *gin.Context) {
-	categories, err := h.service.GetAll()
+	categories, err := h.service.GetAll(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H {
-			"error": "Internal server error",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -32,26 +29,22 @@ func (h *CategoryHandler) GetAll(c *gin.Context) {
 }
 
 func (h *CategoryHandler) Create(c *gin.Context) {
-	var newCategory models.Category
-	err := json.NewDecoder(c.Request.Body).Decode(&newCategory)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request",
-		})
+	var req models.CategoryRequest
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	newData, err := h.service.Create(&newCategory)
+	newData, err := h.service.Create(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H {
-			"error": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
+	recordAudit(c, h.audit, models.AuditActionCreate, models.AuditEntityCategory, newData.ID, nil, newData)
+
 	c.JSON(http.StatusCreated, gin.H {
 		"data": newData,
-		"message": "Berhasil disimpan",
+		"message": translate(c, "saved"),
 	})
 }
 
@@ -60,23 +53,13 @@ func (h *CategoryHandler) GetByID(c *gin.Context) {
 	
 	idInt, err := strconv.Atoi(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid category ID",
-		})
+		c.Error(models.NewInvalidIDError("id"))
 		return
 	}
 
-	category, err := h.service.GetByID(idInt)
+	category, err := h.service.GetByID(c.Request.Context(), idInt)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Category not found",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H {
-			"error": "Internal server error",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -85,34 +68,34 @@ func (h *CategoryHandler) GetByID(c *gin.Context) {
 
 func (h *CategoryHandler) Update(c *gin.Context) {
 	id := c.Param("id")
-	var updateCategory models.Category
-	err := json.NewDecoder(c.Request.Body).Decode(&updateCategory)
+	idInt, err := strconv.Atoi(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request",
-		})
+		c.Error(models.NewInvalidIDError("id"))
 		return
 	}
 
-	idInt, err := strconv.Atoi(id)
+	var req models.CategoryRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	before, err := h.service.GetByID(c.Request.Context(), idInt)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid category ID",
-		})
+		c.Error(err)
 		return
 	}
 
-	updated, err := h.service.Update(idInt, &updateCategory)
+	updated, err := h.service.Update(c.Request.Context(), idInt, req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H {
-			"error": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
+	recordAudit(c, h.audit, models.AuditActionUpdate, models.AuditEntityCategory, idInt, before, updated)
+
 	c.JSON(http.StatusOK, gin.H{
 		"data":    updated,
-		"message": "Berhasil diupdate",
+		"message": translate(c, "updated"),
 	})
 }
 
@@ -121,26 +104,22 @@ func (h *CategoryHandler) Delete(c *gin.Context) {
 
 	idInt, err := strconv.Atoi(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid category ID",
-		})
+		c.Error(models.NewInvalidIDError("id"))
 		return
 	}
 
-	if err := h.service.Delete(idInt); err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Category not found",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Internal server error",
-		})
+	before, err := h.service.GetByID(c.Request.Context(), idInt)
+	if err == nil {
+		err = h.service.Delete(c.Request.Context(), idInt)
+	}
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
+	recordAudit(c, h.audit, models.AuditActionDelete, models.AuditEntityCategory, idInt, before, nil)
+
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Berhasil dihapus",
+		"message": translate(c, "deleted"),
 	})
 }
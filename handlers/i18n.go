@@ -0,0 +1,13 @@
+package handlers
+
+import (
+	"kasir-api/i18n"
+	"kasir-api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// translate returns the message for key in the language of the request.
+func translate(c *gin.Context, key string) string {
+	return i18n.T(middleware.GetLanguage(c), key, nil)
+}
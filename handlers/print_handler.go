@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PrintHandler struct {
+	service *services.PrintService
+	audit   *services.AuditService
+}
+
+func NewPrintHandler(service *services.PrintService, audit *services.AuditService) *PrintHandler {
+	return &PrintHandler{service: service, audit: audit}
+}
+
+func (h *PrintHandler) Print(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(models.NewInvalidIDError("id"))
+		return
+	}
+
+	// The body is optional; without one the default printer is used.
+	var req models.PrintRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil && err != io.EOF {
+		c.Error(models.ErrInvalidBody)
+		return
+	}
+
+	if !validateRequest(c, &req) {
+		return
+	}
+
+	job, err := h.service.Print(c.Request.Context(), idInt, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.audit, models.AuditActionPrint, models.AuditEntityTransaction, idInt, nil, job)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"data":    job,
+		"message": translate(c, "print_queued"),
+	})
+}
+
+func (h *PrintHandler) GetJob(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(models.NewInvalidIDError("id"))
+		return
+	}
+
+	job, err := h.service.GetJob(c.Request.Context(), idInt)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+func (h *PrintHandler) GetJobsByTransactionID(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(models.NewInvalidIDError("id"))
+		return
+	}
+
+	jobs, err := h.service.GetJobsByTransactionID(c.Request.Context(), idInt)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, jobs)
+}
@@ -1,8 +1,6 @@
 package handlers
 
 import (
-	"database/sql"
-	"encoding/json"
 	"kasir-api/models"
 	"kasir-api/services"
 	"net/http"
@@ -13,81 +11,126 @@ import (
 
 type ProductHandler struct {
 	service *services.ProductService
+	audit   *services.AuditService
 }
 
-func NewProductHandler(service *services.ProductService) *ProductHandler {
-	return &ProductHandler{service: service}
+func NewProductHandler(service *services.ProductService, audit *services.AuditService) *ProductHandler {
+	return &ProductHandler{service: service, audit: audit}
 }
 
 func (h *ProductHandler) GetAll(c *gin.Context) {
-	searchQuery := c.Query("name")
-	products, err := h.service.GetAll(searchQuery)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H {
-			"error": "Internal server error",
-		})
+	filter := models.ProductFilter{
+		Name:  c.Query("name"),
+		Sort:  c.Query("sort"),
+		Order: c.Query("order"),
+	}
+	cursor := c.Query("cursor")
+
+	if filter.Sort != "" && !models.IsValidProductSort(filter.Sort) {
+		c.Error(models.NewInvalidFieldError("sort", "oneof", "name price stock created_at"))
+		return
+	}
+	if filter.Order != "" && filter.Order != models.SortAsc && filter.Order != models.SortDesc {
+		c.Error(models.NewInvalidFieldError("order", "oneof", "asc desc"))
 		return
 	}
 
-	c.JSON(200, products)
-}
+	intParams := []struct {
+		name   string
+		target *int
+	}{
+		{"category_id", &filter.CategoryID},
+		{"limit", &filter.Limit},
+		{"offset", &filter.Offset},
+	}
+	for _, p := range intParams {
+		if v := c.Query(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				c.Error(models.NewInvalidFieldError(p.name, "number", ""))
+				return
+			}
+			*p.target = n
+		}
+	}
 
-func (h *ProductHandler) Create(c *gin.Context) {
-	var newProduct models.Product
-	err := json.NewDecoder(c.Request.Body).Decode(&newProduct)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request",
-		})
-		return
+	priceParams := []struct {
+		name   string
+		target **int
+	}{
+		{"min_price", &filter.MinPrice},
+		{"max_price", &filter.MaxPrice},
+	}
+	for _, p := range priceParams {
+		if v := c.Query(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				c.Error(models.NewInvalidFieldError(p.name, "number", ""))
+				return
+			}
+			*p.target = &n
+		}
 	}
 
-	if newProduct.CategoryID == 0 || newProduct.Name == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Kategori produk wajib diisi",
-		})
-		return
+	if v := c.Query("in_stock"); v != "" {
+		inStock, err := strconv.ParseBool(v)
+		if err != nil {
+			c.Error(models.NewInvalidFieldError("in_stock", "boolean", ""))
+			return
+		}
+		filter.InStock = &inStock
 	}
 
-	if newProduct.Name == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Nama produk wajib diisi",
-		})
+	if filter.Offset < 0 {
+		c.Error(models.NewInvalidFieldError("offset", "gte", "0"))
+		return
+	}
+	if filter.Offset > 0 && cursor != "" {
+		c.Error(models.NewInvalidFieldError("offset", "excluded_with", "cursor"))
 		return
 	}
 
-	if newProduct.Price <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Harga produk wajib diisi dan harus lebih dari 0",
-		})
+	products, pagination, err := h.service.GetAll(c.Request.Context(), filter, cursor)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	if newProduct.Stock <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Stok produk wajib diisi dan harus lebih dari 0",
-		})
+	c.JSON(http.StatusOK, gin.H{
+		"data":       products,
+		"pagination": pagination,
+	})
+}
+
+func (h *ProductHandler) Create(c *gin.Context) {
+	var req models.ProductRequest
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	newData, err := h.service.Create(&newProduct)
+	newData, err := h.service.Create(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H {
-			"error": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
+	recordAudit(c, h.audit, models.AuditActionCreate, models.AuditEntityProduct, newData.ID, nil, newData)
+
 	c.JSON(http.StatusCreated, gin.H {
 		"data": gin.H{
 			"id":          newData.ID,
 			"category_id": newData.CategoryID,
+			"sku":         newData.SKU,
+			"barcodes":    newData.Barcodes,
+			"plu":         newData.PLU,
 			"name":        newData.Name,
 			"price":       newData.Price,
+			"cost":        newData.Cost,
 			"stock":       newData.Stock,
+			"tax_exempt":  newData.TaxExempt,
 			"created_at":  newData.CreatedAt,
 		},
-		"message": "Berhasil disimpan",
+		"message": translate(c, "saved"),
 	})
 }
 
@@ -96,100 +139,83 @@ func (h *ProductHandler) GetByID(c *gin.Context) {
 	
 	idInt, err := strconv.Atoi(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid product ID",
-		})
+		c.Error(models.NewInvalidIDError("id"))
 		return
 	}
 
-	product, err := h.service.GetByID(idInt)
+	product, err := h.service.GetByID(c.Request.Context(), idInt)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Product not found",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H {
-			"error": "Internal server error",
-		})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"id":          product.ID,
 		"category_id": product.CategoryID,
+		"sku":         product.SKU,
+		"barcodes":    product.Barcodes,
+		"plu":         product.PLU,
 		"name":        product.Name,
 		"price":       product.Price,
+		"cost":        product.Cost,
 		"stock":       product.Stock,
+		"tax_exempt":  product.TaxExempt,
 		"created_at":  product.CreatedAt,
 	})
 }
 
-func (h *ProductHandler) Update(c *gin.Context) {
-	id := c.Param("id")
-	var updateProduct models.Product
-	err := json.NewDecoder(c.Request.Body).Decode(&updateProduct)
+// GetByBarcode looks up the product for a scanned barcode, including scale
+// labels. It does no audit or extra reads, to keep scanning fast.
+func (h *ProductHandler) GetByBarcode(c *gin.Context) {
+	product, err := h.service.GetByBarcode(c.Request.Context(), c.Param("code"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request",
-		})
+		c.Error(err)
 		return
 	}
+	c.JSON(http.StatusOK, product)
+}
 
+func (h *ProductHandler) Update(c *gin.Context) {
+	id := c.Param("id")
 	idInt, err := strconv.Atoi(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid product ID",
-		})
-		return
-	}
-
-	if updateProduct.CategoryID == 0 || updateProduct.Name == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Kategori produk wajib diisi",
-		})
-		return
-	}
-
-	if updateProduct.Name == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Nama produk wajib diisi",
-		})
+		c.Error(models.NewInvalidIDError("id"))
 		return
 	}
 
-	if updateProduct.Price <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Harga produk wajib diisi dan harus lebih dari 0",
-		})
+	var req models.ProductRequest
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	if updateProduct.Stock <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Stok produk wajib diisi dan harus lebih dari 0",
-		})
+	before, err := h.service.GetByID(c.Request.Context(), idInt)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	updated, err := h.service.Update(idInt, &updateProduct)
+	updated, err := h.service.Update(c.Request.Context(), idInt, req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H {
-			"error": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
+	recordAudit(c, h.audit, models.AuditActionUpdate, models.AuditEntityProduct, idInt, before, updated)
+
 	c.JSON(http.StatusOK, gin.H{
 		"data": gin.H{
 			"id":          updated.ID,
 			"category_id": updated.CategoryID,
+			"sku":         updated.SKU,
+			"barcodes":    updated.Barcodes,
+			"plu":         updated.PLU,
 			"name":        updated.Name,
 			"price":       updated.Price,
+			"cost":        updated.Cost,
 			"stock":       updated.Stock,
+			"tax_exempt":  updated.TaxExempt,
 			"created_at":  updated.CreatedAt,
 		},
-		"message": "Berhasil diupdate",
+		"message": translate(c, "updated"),
 	})
 }
 
@@ -198,26 +224,22 @@ func (h *ProductHandler) Delete(c *gin.Context) {
 
 	idInt, err := strconv.Atoi(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid product ID",
-		})
+		c.Error(models.NewInvalidIDError("id"))
 		return
 	}
 
-	if err := h.service.Delete(idInt); err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Product not found",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Internal server error",
-		})
+	before, err := h.service.GetByID(c.Request.Context(), idInt)
+	if err == nil {
+		err = h.service.Delete(c.Request.Context(), idInt)
+	}
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
+	recordAudit(c, h.audit, models.AuditActionDelete, models.AuditEntityProduct, idInt, before, nil)
+
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Berhasil dihapus",
+		"message": translate(c, "deleted"),
 	})
 }
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PromotionHandler struct {
+	service *services.PromotionService
+	audit   *services.AuditService
+}
+
+func NewPromotionHandler(service *services.PromotionService, audit *services.AuditService) *PromotionHandler {
+	return &PromotionHandler{service: service, audit: audit}
+}
+
+// validatePromotion returns every problem found in p, or nil when it is a
+// usable promotion. Which fields are required depends on the scope and type,
+// so these rules are checked here rather than with validate tags.
+func validatePromotion(p *models.Promotion) error {
+	fields := make([]models.FieldError, 0)
+	invalid := func(field, rule, param string) {
+		fields = append(fields, models.NewFieldError(field, rule, param))
+	}
+
+	if p.Name == "" {
+		invalid("name", "required", "")
+	}
+
+	switch p.Scope {
+	case models.PromotionScopeProduct:
+		if p.ProductID == nil || *p.ProductID <= 0 {
+			invalid("product_id", "required", "")
+		}
+	case models.PromotionScopeCategory:
+		if p.CategoryID == nil || *p.CategoryID <= 0 {
+			invalid("category_id", "required", "")
+		}
+	case models.PromotionScopeCart:
+	default:
+		invalid("scope", "oneof", "product category cart")
+	}
+
+	switch p.Type {
+	case models.PromotionTypePercentage:
+		if p.Value <= 0 {
+			invalid("value", "gt", "0")
+		} else if p.Value > 100 {
+			invalid("value", "lte", "100")
+		}
+	case models.PromotionTypeFixed:
+		if p.Value <= 0 {
+			invalid("value", "gt", "0")
+		}
+	case models.PromotionTypeBuyXGetY:
+		if p.Scope == models.PromotionScopeCart {
+			invalid("scope", "oneof", "product category")
+		}
+		if p.BuyQty <= 0 {
+			invalid("buy_qty", "gt", "0")
+		}
+		if p.GetQty <= 0 {
+			invalid("get_qty", "gt", "0")
+		}
+	default:
+		invalid("type", "oneof", "percentage fixed buy_x_get_y")
+	}
+
+	if p.MinSpend < 0 {
+		invalid("min_spend", "gte", "0")
+	}
+	if p.MaxDiscount < 0 {
+		invalid("max_discount", "gte", "0")
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && p.EndsAt.Before(*p.StartsAt) {
+		invalid("ends_at", "gtfield", "starts_at")
+	}
+
+	if len(fields) > 0 {
+		return models.NewFieldErrors(fields...)
+	}
+	return nil
+}
+
+func (h *PromotionHandler) GetAll(c *gin.Context) {
+	promotions, err := h.service.GetAll(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, promotions)
+}
+
+func (h *PromotionHandler) Create(c *gin.Context) {
+	newPromotion := models.Promotion{Active: true}
+	if !decodeJSON(c, &newPromotion) {
+		return
+	}
+
+	if err := validatePromotion(&newPromotion); err != nil {
+		c.Error(err)
+		return
+	}
+
+	newData, err := h.service.Create(c.Request.Context(), &newPromotion)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.audit, models.AuditActionCreate, models.AuditEntityPromotion, newData.ID, nil, newData)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    newData,
+		"message": translate(c, "saved"),
+	})
+}
+
+func (h *PromotionHandler) GetByID(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(models.NewInvalidIDError("id"))
+		return
+	}
+
+	promotion, err := h.service.GetByID(c.Request.Context(), idInt)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, promotion)
+}
+
+func (h *PromotionHandler) Update(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(models.NewInvalidIDError("id"))
+		return
+	}
+
+	updatePromotion := models.Promotion{Active: true}
+	if !decodeJSON(c, &updatePromotion) {
+		return
+	}
+
+	if err := validatePromotion(&updatePromotion); err != nil {
+		c.Error(err)
+		return
+	}
+
+	before, err := h.service.GetByID(c.Request.Context(), idInt)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	updated, err := h.service.Update(c.Request.Context(), idInt, &updatePromotion)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.audit, models.AuditActionUpdate, models.AuditEntityPromotion, idInt, before, updated)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    updated,
+		"message": translate(c, "updated"),
+	})
+}
+
+func (h *PromotionHandler) Delete(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(models.NewInvalidIDError("id"))
+		return
+	}
+
+	before, err := h.service.GetByID(c.Request.Context(), idInt)
+	if err == nil {
+		err = h.service.Delete(c.Request.Context(), idInt)
+	}
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.audit, models.AuditActionDelete, models.AuditEntityPromotion, idInt, before, nil)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": translate(c, "deleted"),
+	})
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"fmt"
+	"kasir-api/models"
+	"kasir-api/receipt"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReceiptHandler struct {
+	service      *services.TransactionService
+	store        receipt.Store
+	defaultPaper int
+}
+
+func NewReceiptHandler(service *services.TransactionService, store receipt.Store, defaultPaper int) *ReceiptHandler {
+	if !receipt.IsValidPaper(defaultPaper) {
+		defaultPaper = receipt.Paper58mm
+	}
+	return &ReceiptHandler{service: service, store: store, defaultPaper: defaultPaper}
+}
+
+func (h *ReceiptHandler) GetReceipt(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(models.NewInvalidIDError("id"))
+		return
+	}
+
+	paper := h.defaultPaper
+	if v := c.Query("width"); v != "" {
+		paper, err = strconv.Atoi(v)
+		if err != nil || !receipt.IsValidPaper(paper) {
+			c.Error(models.NewInvalidFieldError("width", "oneof", "58 80"))
+			return
+		}
+	}
+
+	format := c.DefaultQuery("format", "text")
+	if format != "text" && format != "escpos" && format != "pdf" {
+		c.Error(models.NewInvalidFieldError("format", "oneof", "text escpos pdf"))
+		return
+	}
+
+	transaction, err := h.service.GetByID(c.Request.Context(), idInt)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	r := receipt.Build(h.store, transaction, paper)
+	switch format {
+	case "escpos":
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=receipt-%d.bin", transaction.ID))
+		c.Data(http.StatusOK, "application/octet-stream", r.ESCPOS())
+	case "pdf":
+		c.Header("Content-Disposition", fmt.Sprintf("inline; filename=receipt-%d.pdf", transaction.ID))
+		c.Data(http.StatusOK, "application/pdf", r.PDF())
+	default:
+		c.String(http.StatusOK, r.Text())
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReturnHandler struct {
+	service *services.ReturnService
+	audit   *services.AuditService
+}
+
+func NewReturnHandler(service *services.ReturnService, audit *services.AuditService) *ReturnHandler {
+	return &ReturnHandler{service: service, audit: audit}
+}
+
+func (h *ReturnHandler) Create(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(models.NewInvalidIDError("id"))
+		return
+	}
+
+	var req models.ReturnRequest
+	if !decodeJSON(c, &req) {
+		return
+	}
+
+	req.PerformedBy = actorName(c)
+	if !validateRequest(c, &req) {
+		return
+	}
+
+	ret, err := h.service.Create(c.Request.Context(), idInt, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.audit, models.AuditActionReturn, models.AuditEntityTransaction, idInt, nil, ret)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    ret,
+		"message": translate(c, "return_saved"),
+	})
+}
+
+func (h *ReturnHandler) GetByTransactionID(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(models.NewInvalidIDError("id"))
+		return
+	}
+
+	returns, err := h.service.GetByTransactionID(c.Request.Context(), idInt)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, returns)
+}
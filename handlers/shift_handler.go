@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"fmt"
+	"kasir-api/models"
+	"kasir-api/receipt"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ShiftHandler struct {
+	service      *services.ShiftService
+	audit        *services.AuditService
+	store        receipt.Store
+	defaultPaper int
+}
+
+func NewShiftHandler(service *services.ShiftService, audit *services.AuditService, store receipt.Store, defaultPaper int) *ShiftHandler {
+	if !receipt.IsValidPaper(defaultPaper) {
+		defaultPaper = receipt.Paper58mm
+	}
+	return &ShiftHandler{service: service, audit: audit, store: store, defaultPaper: defaultPaper}
+}
+
+func (h *ShiftHandler) Open(c *gin.Context) {
+	var req models.OpenShiftRequest
+	if !decodeJSON(c, &req) {
+		return
+	}
+
+	req.CashierName = actorName(c)
+	if !validateRequest(c, &req) {
+		return
+	}
+
+	shift, err := h.service.Open(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.audit, models.AuditActionOpen, models.AuditEntityShift, shift.ID, nil, shift)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    shift,
+		"message": translate(c, "shift_opened"),
+	})
+}
+
+func (h *ShiftHandler) GetAll(c *gin.Context) {
+	status := c.Query("status")
+	switch status {
+	case "", models.ShiftStatusOpen, models.ShiftStatusClosed:
+	default:
+		c.Error(models.NewInvalidFieldError("status", "oneof", "open closed"))
+		return
+	}
+
+	shifts, err := h.service.GetAll(c.Request.Context(), status)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, shifts)
+}
+
+func (h *ShiftHandler) GetByID(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(models.NewInvalidIDError("id"))
+		return
+	}
+
+	shift, err := h.service.GetByID(c.Request.Context(), idInt)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, shift)
+}
+
+func (h *ShiftHandler) AddCashMovement(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(models.NewInvalidIDError("id"))
+		return
+	}
+
+	var m models.CashMovement
+	if !bindJSON(c, &m) {
+		return
+	}
+
+	movement, err := h.service.AddCashMovement(c.Request.Context(), idInt, &m)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.audit, models.AuditActionCashMovement, models.AuditEntityShift, idInt, nil, movement)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    movement,
+		"message": translate(c, "saved"),
+	})
+}
+
+func (h *ShiftHandler) Close(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(models.NewInvalidIDError("id"))
+		return
+	}
+
+	var req models.CloseShiftRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	summary, err := h.service.Close(c.Request.Context(), idInt, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.audit, models.AuditActionClose, models.AuditEntityShift, idInt, nil, summary)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    summary,
+		"message": translate(c, "shift_closed"),
+	})
+}
+
+// GetSummary returns the X or Z report as JSON, or printable with format
+// text, escpos or pdf.
+func (h *ShiftHandler) GetSummary(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(models.NewInvalidIDError("id"))
+		return
+	}
+
+	paper := h.defaultPaper
+	if v := c.Query("width"); v != "" {
+		paper, err = strconv.Atoi(v)
+		if err != nil || !receipt.IsValidPaper(paper) {
+			c.Error(models.NewInvalidFieldError("width", "oneof", "58 80"))
+			return
+		}
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "text" && format != "escpos" && format != "pdf" {
+		c.Error(models.NewInvalidFieldError("format", "oneof", "json text escpos pdf"))
+		return
+	}
+
+	summary, err := h.service.GetSummary(c.Request.Context(), idInt)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, summary)
+		return
+	}
+
+	r := receipt.BuildShiftSummary(h.store, summary, paper)
+	switch format {
+	case "escpos":
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=shift-%d.bin", summary.Shift.ID))
+		c.Data(http.StatusOK, "application/octet-stream", r.ESCPOS())
+	case "pdf":
+		c.Header("Content-Disposition", fmt.Sprintf("inline; filename=shift-%d.pdf", summary.Shift.ID))
+		c.Data(http.StatusOK, "application/pdf", r.PDF())
+	default:
+		c.String(http.StatusOK, r.Text())
+	}
+}
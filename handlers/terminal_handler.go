@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"fmt"
+	"kasir-api/middleware"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TerminalHandler struct {
+	service *services.TerminalService
+	audit   *services.AuditService
+}
+
+func NewTerminalHandler(service *services.TerminalService, audit *services.AuditService) *TerminalHandler {
+	return &TerminalHandler{service: service, audit: audit}
+}
+
+func (h *TerminalHandler) GetAll(c *gin.Context) {
+	terminals, err := h.service.GetAll(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, terminals)
+}
+
+func (h *TerminalHandler) Create(c *gin.Context) {
+	var req models.TerminalRequest
+	if !bindJSON(c, &req) || !checkHeldPermissions(c, req) {
+		return
+	}
+
+	terminal, err := h.service.Create(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.audit, models.AuditActionCreate, models.AuditEntityTerminal, terminal.ID, nil, terminal)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    terminal,
+		"message": translate(c, "saved"),
+	})
+}
+
+func (h *TerminalHandler) GetByID(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(models.NewInvalidIDError("id"))
+		return
+	}
+
+	terminal, err := h.service.GetByID(c.Request.Context(), idInt)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, terminal)
+}
+
+func (h *TerminalHandler) Update(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(models.NewInvalidIDError("id"))
+		return
+	}
+
+	var req models.TerminalRequest
+	if !bindJSON(c, &req) || !checkHeldPermissions(c, req) {
+		return
+	}
+
+	before, err := h.service.GetByID(c.Request.Context(), idInt)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	terminal, err := h.service.Update(c.Request.Context(), idInt, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.audit, models.AuditActionUpdate, models.AuditEntityTerminal, idInt, before, terminal)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    terminal,
+		"message": translate(c, "updated"),
+	})
+}
+
+// checkHeldPermissions records an error on c when req grants a permission
+// the caller does not hold, so nobody can hand a terminal more than they
+// may do themselves.
+func checkHeldPermissions(c *gin.Context, req models.TerminalRequest) bool {
+	p := middleware.CurrentPrincipal(c)
+	if p == nil {
+		c.Error(models.ErrUnauthorized)
+		return false
+	}
+
+	fields := make([]models.FieldError, 0)
+	for i, permission := range req.Permissions {
+		if !p.Can(permission) {
+			fields = append(fields, models.NewFieldError(fmt.Sprintf("permissions[%d]", i), "held", ""))
+		}
+	}
+	if len(fields) > 0 {
+		c.Error(models.NewFieldErrors(fields...))
+		return false
+	}
+	return true
+}
+
+func (h *TerminalHandler) IssueAPIKey(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(models.NewInvalidIDError("id"))
+		return
+	}
+
+	key, err := h.service.IssueAPIKey(c.Request.Context(), idInt)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Only the stored part of the key is logged, never the key itself.
+	recordAudit(c, h.audit, models.AuditActionIssueKey, models.AuditEntityTerminal, idInt, nil, key.TerminalAPIKey)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    key,
+		"message": translate(c, "api_key_issued"),
+	})
+}
+
+func (h *TerminalHandler) GetAPIKeys(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(models.NewInvalidIDError("id"))
+		return
+	}
+
+	keys, err := h.service.GetAPIKeys(c.Request.Context(), idInt)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+func (h *TerminalHandler) RevokeAPIKey(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(models.NewInvalidIDError("id"))
+		return
+	}
+	keyID, err := strconv.Atoi(c.Param("keyId"))
+	if err != nil {
+		c.Error(models.NewInvalidIDError("keyId"))
+		return
+	}
+
+	key, err := h.service.RevokeAPIKey(c.Request.Context(), idInt, keyID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.audit, models.AuditActionRevokeKey, models.AuditEntityTerminal, idInt, nil, key)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    key,
+		"message": translate(c, "api_key_revoked"),
+	})
+}
@@ -1,71 +1,224 @@
 package handlers
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"kasir-api/middleware"
 	"kasir-api/models"
 	"kasir-api/services"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 type TransactionHandler struct {
-	service *services.TransactionService
+	service      *services.TransactionService
+	printService *services.PrintService
+	audit        *services.AuditService
+	useLock      bool
 }
 
-func NewTransactionHandler(service *services.TransactionService) *TransactionHandler {
-	return &TransactionHandler{service: service}
+func NewTransactionHandler(service *services.TransactionService, printService *services.PrintService, audit *services.AuditService, useLock bool) *TransactionHandler {
+	return &TransactionHandler{service: service, printService: printService, audit: audit, useLock: useLock}
+}
+
+// idempotencyScope names the caller an Idempotency-Key belongs to: the
+// terminal for terminal sessions, the user otherwise.
+func idempotencyScope(p *middleware.Principal) string {
+	switch {
+	case p == nil:
+		return ""
+	case p.TerminalID != 0:
+		return "terminal:" + strconv.Itoa(p.TerminalID)
+	}
+	return "user:" + strconv.Itoa(p.UserID)
 }
 
 func (h *TransactionHandler) Checkout(c *gin.Context) {
 	var req models.CheckoutRequest
-	err := json.NewDecoder(c.Request.Body).Decode(&req)
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	var key *models.IdempotencyKey
+	if header := c.GetHeader("Idempotency-Key"); header != "" {
+		if len(header) > 255 {
+			c.Error(models.NewInvalidFieldError("Idempotency-Key", "max", "255"))
+			return
+		}
+		// Hash the decoded request rather than the raw body so that retries
+		// differing only in whitespace or key order still match.
+		body, err := json.Marshal(req)
+		if err != nil {
+			c.Error(models.ErrInvalidBody)
+			return
+		}
+		sum := sha256.Sum256(body)
+		key = &models.IdempotencyKey{
+			Scope: idempotencyScope(middleware.CurrentPrincipal(c)),
+			Key: header,
+			RequestHash: hex.EncodeToString(sum[:]),
+		}
+	}
+
+	terminalID := 0
+	if p := middleware.CurrentPrincipal(c); p != nil {
+		terminalID = p.TerminalID
+	}
+
+	transaction, replayed, err := h.service.Checkout(c.Request.Context(), req, h.useLock, key, terminalID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H {
-			"error": "Invalid request body",
-		})
+		c.Error(err)
+		return
+	}
+
+	if replayed {
+		c.Header("Idempotent-Replayed", "true")
+	} else {
+		recordAudit(c, h.audit, models.AuditActionCheckout, models.AuditEntityTransaction, transaction.ID, nil, transaction)
+		h.printService.AfterCheckout(c.Request.Context(), transaction)
+	}
+	c.JSON(http.StatusOK, transaction)
+}
+
+func (h *TransactionHandler) GetAll(c *gin.Context) {
+	filter := models.TransactionFilter{
+		StartDate: c.Query("start_date"),
+		EndDate:   c.Query("end_date"),
+		Status:    c.Query("status"),
+	}
+
+	switch filter.Status {
+	case "", models.TransactionStatusCompleted, models.TransactionStatusVoided, models.TransactionStatusRefunded:
+	default:
+		c.Error(models.NewInvalidFieldError("status", "oneof", "completed voided refunded"))
 		return
 	}
 
-	if len(req.Items) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Items tidak boleh kosong",
-		})
+	filter.PaymentMethod = c.Query("payment_method")
+	if filter.PaymentMethod != "" && !models.IsValidPaymentMethod(filter.PaymentMethod) {
+		c.Error(models.NewInvalidFieldError("payment_method", "oneof", "cash debit_card qris ewallet transfer"))
 		return
 	}
 
-	for _, item := range req.Items {
-		if item.ProductID == 0 {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "product_id wajib diisi dan harus lebih dari 0",
-			})
-			return
+	intParams := []struct {
+		name   string
+		target *int
+	}{
+		{"page", &filter.Page},
+		{"limit", &filter.Limit},
+		{"product_id", &filter.ProductID},
+		{"shift_id", &filter.ShiftID},
+		{"terminal_id", &filter.TerminalID},
+	}
+	for _, p := range intParams {
+		if v := c.Query(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				c.Error(models.NewInvalidFieldError(p.name, "number", ""))
+				return
+			}
+			*p.target = n
 		}
-		if item.Quantity <= 0 {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "quantity wajib diisi dan harus lebih dari 0",
-			})
-			return
+	}
+
+	amountParams := []struct {
+		name   string
+		target **int
+	}{
+		{"min_amount", &filter.MinAmount},
+		{"max_amount", &filter.MaxAmount},
+	}
+	for _, p := range amountParams {
+		if v := c.Query(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				c.Error(models.NewInvalidFieldError(p.name, "number", ""))
+				return
+			}
+			*p.target = &n
 		}
 	}
 
-	transaction, err := h.service.Checkout(req.Items, false)
+	transactions, pagination, err := h.service.GetAll(c.Request.Context(), filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H {
-			"error": err.Error(),
-		})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       transactions,
+		"pagination": pagination,
+	})
+}
+
+func (h *TransactionHandler) GetByID(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(models.NewInvalidIDError("id"))
+		return
+	}
+
+	transaction, err := h.service.GetByID(c.Request.Context(), idInt)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, transaction)
 }
 
+func (h *TransactionHandler) Void(c *gin.Context) {
+	h.cancel(c, h.service.Void, models.AuditActionVoid, "transaction_voided")
+}
+
+func (h *TransactionHandler) Refund(c *gin.Context) {
+	h.cancel(c, h.service.Refund, models.AuditActionRefund, "transaction_refunded")
+}
+
+func (h *TransactionHandler) cancel(c *gin.Context, cancelFn func(context.Context, int, models.CancelTransactionRequest) (*models.Transaction, error), action, messageKey string) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(models.NewInvalidIDError("id"))
+		return
+	}
+
+	var req models.CancelTransactionRequest
+	if !decodeJSON(c, &req) {
+		return
+	}
+
+	req.PerformedBy = actorName(c)
+	if !validateRequest(c, &req) {
+		return
+	}
+
+	var before interface{}
+	if t, err := h.service.GetByID(c.Request.Context(), idInt); err == nil {
+		before = t
+	}
+
+	transaction, err := cancelFn(c.Request.Context(), idInt, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.audit, action, models.AuditEntityTransaction, idInt, before, transaction)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    transaction,
+		"message": translate(c, messageKey),
+	})
+}
+
 func (h *TransactionHandler) GetReport(c *gin.Context) {
-	report, err := h.service.GetReport()
+	report, err := h.service.GetReport(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H {
-			"error": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -73,23 +226,85 @@ func (h *TransactionHandler) GetReport(c *gin.Context) {
 }
 
 func (h *TransactionHandler) GetReportByDateRange(c *gin.Context) {
+	startDate, endDate, ok := requiredDateRange(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.GetReportByDateRange(c.Request.Context(), startDate, endDate)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetReportV2 is GetReport in the version 2 shape.
+func (h *TransactionHandler) GetReportV2(c *gin.Context) {
+	report, err := h.service.GetReport(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report.V2())
+}
+
+// GetReportByDateRangeV2 is GetReportByDateRange in the version 2 shape.
+func (h *TransactionHandler) GetReportByDateRangeV2(c *gin.Context) {
+	startDate, endDate, ok := requiredDateRange(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.GetReportByDateRange(c.Request.Context(), startDate, endDate)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report.V2())
+}
+
+// requiredDateRange reads the start_date and end_date query parameters,
+// recording an error on c when either is missing.
+func requiredDateRange(c *gin.Context) (string, string, bool) {
+	startDate := c.Query("start_date")
+	endDate := c.Query("end_date")
+
+	fields := make([]models.FieldError, 0)
+	if startDate == "" {
+		fields = append(fields, models.NewFieldError("start_date", "required", ""))
+	}
+	if endDate == "" {
+		fields = append(fields, models.NewFieldError("end_date", "required", ""))
+	}
+	if len(fields) > 0 {
+		c.Error(models.NewFieldErrors(fields...))
+		return "", "", false
+	}
+	return startDate, endDate, true
+}
+
+func (h *TransactionHandler) GetTaxSummary(c *gin.Context) {
 	startDate := c.Query("start_date")
 	endDate := c.Query("end_date")
 
-	if startDate == "" || endDate == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "start_date dan end_date wajib diisi",
-		})
+	if startDate == "" && endDate != "" {
+		c.Error(models.NewInvalidFieldError("start_date", "required_with", "end_date"))
+		return
+	}
+	if endDate == "" && startDate != "" {
+		c.Error(models.NewInvalidFieldError("end_date", "required_with", "start_date"))
 		return
 	}
 
-	report, err := h.service.GetReportByDateRange(startDate, endDate)
+	summary, err := h.service.GetTaxSummary(c.Request.Context(), startDate, endDate)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H {
-			"error": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, report)
+	c.JSON(http.StatusOK, summary)
 }
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UserHandler struct {
+	service *services.UserService
+	audit   *services.AuditService
+}
+
+func NewUserHandler(service *services.UserService, audit *services.AuditService) *UserHandler {
+	return &UserHandler{service: service, audit: audit}
+}
+
+func (h *UserHandler) GetAll(c *gin.Context) {
+	users, err := h.service.GetAll(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+func (h *UserHandler) Create(c *gin.Context) {
+	var req models.CreateUserRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, err := h.service.Create(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.audit, models.AuditActionCreate, models.AuditEntityUser, user.ID, nil, user)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    user,
+		"message": translate(c, "saved"),
+	})
+}
+
+func (h *UserHandler) Update(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(models.NewInvalidIDError("id"))
+		return
+	}
+
+	var req models.UpdateUserRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	before, err := h.service.GetByID(c.Request.Context(), idInt)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	user, err := h.service.Update(c.Request.Context(), idInt, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordAudit(c, h.audit, models.AuditActionUpdate, models.AuditEntityUser, idInt, before, user)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    user,
+		"message": translate(c, "updated"),
+	})
+}
@@ -0,0 +1,103 @@
+// Package i18n holds the API's Indonesian and English messages. Error
+// messages are keyed by the error code, field validation messages by the
+// rule, and other messages by a short key such as "saved".
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	Indonesian = "id"
+	English    = "en"
+	// Default is used when the client asks for no supported language.
+	Default = Indonesian
+)
+
+// Supported reports whether lang has a message catalog.
+func Supported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// Negotiate picks the supported language the client prefers most from an
+// Accept-Language header such as "en-US,en;q=0.9,id;q=0.8". Region
+// subtags are ignored.
+func Negotiate(acceptLanguage string) string {
+	type choice struct {
+		lang string
+		q    float64
+	}
+	choices := make([]choice, 0)
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if !Supported(base) {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			choices = append(choices, choice{lang: base, q: q})
+		}
+	}
+	if len(choices) == 0 {
+		return Default
+	}
+	// Stable, so the first of equally weighted languages wins.
+	sort.SliceStable(choices, func(i, j int) bool {
+		return choices[i].q > choices[j].q
+	})
+	return choices[0].lang
+}
+
+// Lookup returns the message for key in lang, falling back to Default.
+func Lookup(lang, key string) (string, bool) {
+	if msg, ok := catalogs[lang][key]; ok {
+		return msg, true
+	}
+	msg, ok := catalogs[Default][key]
+	return msg, ok
+}
+
+// T returns the message for key in lang with each {name} placeholder
+// replaced from params. Unknown keys are returned as is.
+func T(lang, key string, params map[string]string) string {
+	msg, ok := Lookup(lang, key)
+	if !ok {
+		return key
+	}
+	return render(msg, params)
+}
+
+// FieldMessage describes a field that broke rule. Rules without a message
+// of their own get a generic "field is invalid".
+func FieldMessage(lang, field, rule, param string) string {
+	msg, ok := Lookup(lang, "rule."+rule)
+	if !ok {
+		msg, _ = Lookup(lang, "rule.invalid")
+	}
+	if rule == "oneof" {
+		param = strings.Join(strings.Fields(param), ", ")
+	}
+	return render(msg, map[string]string{"field": field, "param": param})
+}
+
+func render(msg string, params map[string]string) string {
+	if len(params) == 0 {
+		return msg
+	}
+	pairs := make([]string, 0, len(params)*2)
+	for name, value := range params {
+		pairs = append(pairs, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(pairs...).Replace(msg)
+}
@@ -0,0 +1,180 @@
+package i18n
+
+// catalogs maps a language to its messages. Every key in the Indonesian
+// catalog should also be in the English one; a missing key falls back to
+// Indonesian.
+var catalogs = map[string]map[string]string{
+	Indonesian: {
+		// Errors, keyed by models.Error code.
+		"invalid_body":                "Body request tidak valid",
+		"invalid_id":                  "{param} harus berupa angka",
+		"validation_failed":           "Data request tidak valid",
+		"internal_error":              "Terjadi kesalahan pada server",
+		"timeout":                     "Request melebihi batas waktu",
+		"unauthorized":                "Authorization Bearer token atau X-API-Key wajib diisi",
+		"forbidden":                   "Anda tidak memiliki akses ke resource ini",
+		"route_not_found":             "Endpoint tidak ditemukan",
+		"invalid_credentials":         "Username atau password salah",
+		"invalid_token":               "Refresh token tidak valid atau sudah kedaluwarsa",
+		"invalid_access_token":        "Token tidak valid atau sudah kedaluwarsa",
+		"invalid_api_key":             "API key tidak valid atau sudah dicabut",
+		"user_login_required":         "Hanya tersedia untuk login user",
+		"username_taken":              "Username sudah dipakai",
+		"sku_taken":                   "SKU sudah dipakai produk lain",
+		"barcode_taken":               "Barcode sudah dipakai produk lain",
+		"plu_taken":                   "PLU sudah dipakai produk lain",
+		"invalid_barcode":             "Barcode timbangan tidak valid",
+		"category_not_found":          "Kategori tidak ditemukan",
+		"product_not_found":           "Produk tidak ditemukan",
+		"promotion_not_found":         "Promo tidak ditemukan",
+		"transaction_not_found":       "Transaksi tidak ditemukan",
+		"print_job_not_found":         "Print job tidak ditemukan",
+		"shift_not_found":             "Shift tidak ditemukan",
+		"user_not_found":              "User tidak ditemukan",
+		"terminal_not_found":          "Terminal tidak ditemukan",
+		"api_key_not_found":           "API key tidak ditemukan",
+		"category_in_use":             "Kategori masih memiliki produk",
+		"invalid_promotion_target":    "product_id atau category_id tidak ditemukan",
+		"insufficient_stock":          "Stok produk tidak mencukupi",
+		"insufficient_payment":        "Pembayaran kurang dari total transaksi",
+		"non_cash_overpayment":        "Pembayaran non-tunai melebihi total transaksi",
+		"idempotency_key_mismatch":    "Idempotency-Key sudah dipakai untuk request yang berbeda",
+		"idempotency_key_in_progress": "Request dengan Idempotency-Key ini sedang diproses",
+		"transaction_not_completed":   "Transaksi sudah dibatalkan atau direfund",
+		"transaction_has_returns":     "Transaksi yang sudah diretur tidak bisa di-void, gunakan refund",
+		"void_shift_closed":           "Shift transaksi sudah ditutup, gunakan refund",
+		"return_detail_not_found":     "Detail transaksi tidak ditemukan pada transaksi ini",
+		"return_quantity_exceeded":    "Jumlah retur melebihi jumlah yang bisa diretur",
+		"shift_not_open":              "Shift tidak ditemukan atau sudah ditutup",
+		"no_open_shift":               "Belum ada shift yang dibuka",
+		"shift_required":              "Lebih dari satu shift terbuka, shift_id wajib diisi",
+		"shift_already_open":          "Kasir masih memiliki shift yang terbuka",
+		"no_printer":                  "printer_address wajib diisi karena printer default belum diatur",
+		"printer_not_allowed":         "printer_address bukan printer yang terdaftar",
+		"unsupported_backup":          "Versi backup tidak didukung",
+		"invalid_backup":              "Isi backup tidak valid",
+
+		// Field validation, keyed by "rule." and the rule name.
+		"rule.required":            "{field} wajib diisi",
+		"rule.required_with":       "{field} wajib diisi bersama {param}",
+		"rule.required_without":    "{field} wajib diisi jika {param} kosong",
+		"rule.gt":                  "{field} harus lebih dari {param}",
+		"rule.gte":                 "{field} tidak boleh kurang dari {param}",
+		"rule.lte":                 "{field} tidak boleh lebih dari {param}",
+		"rule.min":                 "{field} minimal {param} karakter",
+		"rule.max":                 "{field} maksimal {param} karakter",
+		"rule.oneof":               "{field} harus salah satu dari: {param}",
+		"rule.gtfield":             "{field} harus setelah {param}",
+		"rule.min_items":           "{field} minimal berisi {param} item",
+		"rule.max_items":           "{field} maksimal berisi {param} item",
+		"rule.unique":              "{field} tidak boleh berisi nilai yang sama",
+		"rule.type":                "{field} harus bertipe {param}",
+		"rule.number":              "{field} harus berupa angka",
+		"rule.boolean":             "{field} harus bernilai true atau false",
+		"rule.excluded_with":       "{field} tidak boleh diisi bersama {param}",
+		"rule.excludes":            "{field} tidak boleh berisi {param}",
+		"rule.exists":              "{field} tidak ditemukan",
+		"rule.terminal_permission": "{field} tidak dapat diberikan ke terminal",
+		"rule.held":                "{field} tidak dapat diberikan karena Anda sendiri tidak memilikinya",
+		"rule.invalid":             "{field} tidak valid",
+
+		// Success messages.
+		"saved":                "Berhasil disimpan",
+		"updated":              "Berhasil diupdate",
+		"deleted":              "Berhasil dihapus",
+		"logged_out":           "Berhasil logout",
+		"print_queued":         "Struk masuk antrean cetak",
+		"return_saved":         "Retur berhasil disimpan",
+		"shift_opened":         "Shift dibuka",
+		"shift_closed":         "Shift ditutup",
+		"api_key_issued":       "Simpan API key ini, key tidak dapat ditampilkan lagi",
+		"api_key_revoked":      "API key dicabut",
+		"transaction_voided":   "Transaksi berhasil dibatalkan",
+		"transaction_refunded": "Transaksi berhasil direfund",
+	},
+	English: {
+		"invalid_body":                "Invalid request body",
+		"invalid_id":                  "{param} must be a number",
+		"validation_failed":           "Invalid request data",
+		"internal_error":              "Internal server error",
+		"timeout":                     "The request took too long",
+		"unauthorized":                "An Authorization Bearer token or X-API-Key is required",
+		"forbidden":                   "You do not have access to this resource",
+		"route_not_found":             "Endpoint not found",
+		"invalid_credentials":         "Wrong username or password",
+		"invalid_token":               "Refresh token is invalid or has expired",
+		"invalid_access_token":        "Token is invalid or has expired",
+		"invalid_api_key":             "API key is invalid or has been revoked",
+		"user_login_required":         "Only available to logged in users",
+		"username_taken":              "Username is already taken",
+		"sku_taken":                   "SKU is already used by another product",
+		"barcode_taken":               "Barcode is already used by another product",
+		"plu_taken":                   "PLU is already used by another product",
+		"invalid_barcode":             "Invalid scale barcode",
+		"category_not_found":          "Category not found",
+		"product_not_found":           "Product not found",
+		"promotion_not_found":         "Promotion not found",
+		"transaction_not_found":       "Transaction not found",
+		"print_job_not_found":         "Print job not found",
+		"shift_not_found":             "Shift not found",
+		"user_not_found":              "User not found",
+		"terminal_not_found":          "Terminal not found",
+		"api_key_not_found":           "API key not found",
+		"category_in_use":             "Category still has products",
+		"invalid_promotion_target":    "product_id or category_id not found",
+		"insufficient_stock":          "Not enough stock",
+		"insufficient_payment":        "Payment is less than the transaction total",
+		"non_cash_overpayment":        "Non-cash payments exceed the transaction total",
+		"idempotency_key_mismatch":    "Idempotency-Key was already used for a different request",
+		"idempotency_key_in_progress": "A request with this Idempotency-Key is still being processed",
+		"transaction_not_completed":   "Transaction has already been voided or refunded",
+		"transaction_has_returns":     "Transaction has returns and can only be refunded",
+		"void_shift_closed":           "Transaction's shift is closed, so it can only be refunded",
+		"return_detail_not_found":     "Transaction line not found in this transaction",
+		"return_quantity_exceeded":    "Return quantity exceeds what can be returned",
+		"shift_not_open":              "Shift not found or already closed",
+		"no_open_shift":               "No shift is open",
+		"shift_required":              "More than one shift is open, shift_id is required",
+		"shift_already_open":          "The cashier already has an open shift",
+		"no_printer":                  "printer_address is required because no default printer is set",
+		"printer_not_allowed":         "printer_address is not a configured printer",
+		"unsupported_backup":          "Unsupported backup version",
+		"invalid_backup":              "The backup holds invalid rows",
+
+		"rule.required":            "{field} is required",
+		"rule.required_with":       "{field} is required together with {param}",
+		"rule.required_without":    "{field} is required when {param} is empty",
+		"rule.gt":                  "{field} must be greater than {param}",
+		"rule.gte":                 "{field} must be at least {param}",
+		"rule.lte":                 "{field} must be at most {param}",
+		"rule.min":                 "{field} must be at least {param} characters",
+		"rule.max":                 "{field} must be at most {param} characters",
+		"rule.oneof":               "{field} must be one of: {param}",
+		"rule.gtfield":             "{field} must be after {param}",
+		"rule.min_items":           "{field} must have at least {param} item(s)",
+		"rule.max_items":           "{field} must have at most {param} item(s)",
+		"rule.unique":              "{field} must not contain duplicates",
+		"rule.type":                "{field} must be of type {param}",
+		"rule.number":              "{field} must be a number",
+		"rule.boolean":             "{field} must be true or false",
+		"rule.excluded_with":       "{field} cannot be used together with {param}",
+		"rule.excludes":            "{field} must not contain {param}",
+		"rule.exists":              "{field} does not exist",
+		"rule.terminal_permission": "{field} cannot be granted to a terminal",
+		"rule.held":                "{field} cannot be granted because you do not hold it yourself",
+		"rule.invalid":             "{field} is invalid",
+
+		"saved":                "Saved",
+		"updated":              "Updated",
+		"deleted":              "Deleted",
+		"logged_out":           "Logged out",
+		"print_queued":         "Receipt queued for printing",
+		"return_saved":         "Return saved",
+		"shift_opened":         "Shift opened",
+		"shift_closed":         "Shift closed",
+		"api_key_issued":       "Store this API key, it cannot be shown again",
+		"api_key_revoked":      "API key revoked",
+		"transaction_voided":   "Transaction voided",
+		"transaction_refunded": "Transaction refunded",
+	},
+}
@@ -1,60 +1,69 @@
 package main
 
 import (
+	"database/sql"
+	"fmt"
+	"io"
+	"kasir-api/config"
 	"kasir-api/database"
-	"kasir-api/routes"
 	"log"
 	"os"
-	"strings"
-
-	"github.com/gin-contrib/cors"
-	"github.com/gin-gonic/gin"
-	"github.com/spf13/viper"
 )
 
-type Config struct {
-	Port		string `mapstructure:"PORT"`
-	DBConn	string `mapstructure:"DB_CONN"`
+const usage = `usage: kasir-api <command> [arguments]
+
+commands:
+  serve [--port PORT]                       start the HTTP server (default)
+  migrate up | down [steps] | status        manage the database schema
+  seed [--demo]                             create the owner account, and demo catalog with --demo
+  user create --username NAME --role ROLE   create a user, the password is read from stdin
+  user list                                 list users
+  export [--file FILE]                      write the catalog as JSON, to stdout by default
+  import --file FILE                        restore a catalog written by export
+
+Every command reads the same configuration (DB_CONN, ...) as the server.
+`
+
+func printUsage(w io.Writer) {
+	fmt.Fprint(w, usage)
 }
 
 func main() {
-	viper.AutomaticEnv()
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	cfg := config.Load()
 
-	if _, err := os.Stat(".env"); err == nil {
-		viper.SetConfigFile(".env")
-		_ = viper.ReadInConfig()
+	command, args := "serve", []string{}
+	if len(os.Args) > 1 {
+		command, args = os.Args[1], os.Args[2:]
 	}
-	config := Config {
-		Port:		viper.GetString("PORT"),
-		DBConn: viper.GetString("DB_CONN"),
+
+	switch command {
+	case "serve":
+		runServe(cfg, args)
+	case "migrate":
+		runMigrate(cfg, args)
+	case "seed":
+		runSeed(cfg, args)
+	case "user":
+		runUser(cfg, args)
+	case "export":
+		runExport(cfg, args)
+	case "import":
+		runImport(cfg, args)
+	case "help", "-h", "--help":
+		printUsage(os.Stdout)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", command)
+		printUsage(os.Stderr)
+		os.Exit(2)
 	}
+}
 
-	// Connection DB
-	db, err := database.InitDB(config.DBConn)
+// openDB connects the way the server does, migrating or verifying the
+// schema first.
+func openDB(cfg *config.Config) *sql.DB {
+	db, err := database.InitDB(cfg.DBConn, cfg.AutoMigrate)
 	if err != nil {
 		log.Fatal("Failed to initialize database:", err)
 	}
-	defer db.Close()
-
-	router := gin.Default()
-
-	if err := router.SetTrustedProxies([]string{"127.0.0.1"}); err != nil {
-		log.Fatal("Failed to set trusted proxies:", err)
-	}
-
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-	}))
-
-	routes.Routes(router, db)
-
-	log.Printf("Kasir API server is running on port %s", config.Port)
-	if err := router.Run(":" + config.Port); err != nil {
-		log.Fatal("Failed to start server:", err)
-	}
+	return db
 }
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"kasir-api/models"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorResponse is the body of every error response.
+type ErrorResponse struct {
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	Details   interface{} `json:"details"`
+	RequestID string      `json:"request_id"`
+}
+
+var kindStatus = map[models.ErrorKind]int{
+	models.KindInternal:      http.StatusInternalServerError,
+	models.KindValidation:    http.StatusBadRequest,
+	models.KindNotFound:      http.StatusNotFound,
+	models.KindConflict:      http.StatusConflict,
+	models.KindUnprocessable: http.StatusUnprocessableEntity,
+	models.KindUnauthorized:  http.StatusUnauthorized,
+	models.KindForbidden:     http.StatusForbidden,
+	models.KindTimeout:       http.StatusGatewayTimeout,
+}
+
+// Errors writes the response for the last error a handler added with
+// c.Error. Domain errors keep their code and message; anything else is
+// logged and reported as an internal error, so database errors never reach
+// the client. Messages are in the language picked by Language. It must run
+// after RequestID and Language.
+func Errors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		appErr := toDomainError(err)
+		if appErr.Kind == models.KindInternal || appErr.Kind == models.KindTimeout {
+			log.Printf("Request %s %s %s failed: %v", GetRequestID(c), c.Request.Method, c.Request.URL.Path, err)
+		}
+
+		status, ok := kindStatus[appErr.Kind]
+		if !ok {
+			status = http.StatusInternalServerError
+		}
+		lang := GetLanguage(c)
+		c.JSON(status, ErrorResponse{
+			Code:      appErr.Code,
+			Message:   appErr.Message(lang),
+			Details:   appErr.LocalizedDetails(lang),
+			RequestID: GetRequestID(c),
+		})
+	}
+}
+
+func toDomainError(err error) *models.Error {
+	var appErr *models.Error
+	switch {
+	case errors.As(err, &appErr):
+		return appErr
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return models.ErrTimeout
+	default:
+		return models.ErrInternal
+	}
+}
+
+// abort stops the chain and leaves err for Errors to report.
+func abort(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
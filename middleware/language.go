@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"kasir-api/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+const languageKey = "language"
+
+// Language picks the response language from the Accept-Language header,
+// Indonesian unless the client prefers English, and reports it in
+// Content-Language.
+func Language() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lang := i18n.Negotiate(c.GetHeader("Accept-Language"))
+		c.Set(languageKey, lang)
+		c.Header("Content-Language", lang)
+		c.Header("Vary", "Accept-Language")
+		c.Next()
+	}
+}
+
+// GetLanguage returns the language set by Language, or the default when it
+// did not run.
+func GetLanguage(c *gin.Context) string {
+	if lang := c.GetString(languageKey); lang != "" {
+		return lang
+	}
+	return i18n.Default
+}
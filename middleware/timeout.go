@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const baseContextKey = "base_context"
+
+// Timeout gives the request context a deadline d from now, so every query
+// made for the request is cancelled once it passes. A later Timeout on the
+// same route replaces an earlier one instead of being capped by it, so a
+// group can set a default that single routes raise.
+func Timeout(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parent, ok := c.Value(baseContextKey).(context.Context)
+		if !ok {
+			parent = c.Request.Context()
+			c.Set(baseContextKey, parent)
+		}
+
+		ctx, cancel := context.WithTimeout(parent, d)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
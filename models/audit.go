@@ -0,0 +1,71 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	AuditActorUser     = "user"
+	AuditActorTerminal = "terminal"
+	AuditActorSystem   = "system"
+)
+
+const (
+	AuditActionCreate       = "create"
+	AuditActionUpdate       = "update"
+	AuditActionDelete       = "delete"
+	AuditActionCheckout     = "checkout"
+	AuditActionVoid         = "void"
+	AuditActionRefund       = "refund"
+	AuditActionReturn       = "return"
+	AuditActionOpen         = "open"
+	AuditActionClose        = "close"
+	AuditActionCashMovement = "cash_movement"
+	AuditActionIssueKey     = "issue_key"
+	AuditActionRevokeKey    = "revoke_key"
+	AuditActionPrint        = "print"
+	AuditActionImport       = "import"
+)
+
+const (
+	AuditEntityCategory    = "category"
+	AuditEntityProduct     = "product"
+	AuditEntityPromotion   = "promotion"
+	AuditEntityTransaction = "transaction"
+	AuditEntityShift       = "shift"
+	AuditEntityUser        = "user"
+	AuditEntityTerminal    = "terminal"
+	AuditEntityCatalog     = "catalog"
+)
+
+// AuditEntry is one change recorded in the audit log. Before is empty for
+// creations and After for deletions.
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	ActorType  string          `json:"actor_type"`
+	ActorID    *int            `json:"actor_id"`
+	ActorName  string          `json:"actor_name"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	RequestID  string          `json:"request_id"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditFilter narrows the audit log listing. Zero values mean the filter is
+// not applied.
+type AuditFilter struct {
+	ActorType  string
+	ActorID    int
+	Action     string
+	EntityType string
+	EntityID   string
+	RequestID  string
+	StartDate  string
+	EndDate    string
+	Page       int
+	Limit      int
+}
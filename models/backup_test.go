@@ -0,0 +1,68 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCatalogBackupValidate(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	valid := func() *CatalogBackup {
+		return &CatalogBackup{
+			Version:    CatalogBackupVersion,
+			Categories: []Category{{ID: 1, Name: "Minuman"}},
+			Products:   []Product{{ID: 10, CategoryID: 1, Name: "Teh Botol", Price: 5000, Cost: 3500, Stock: 24}},
+			Promotions: []Promotion{{ID: 100, Name: "Diskon teh", ProductID: intPtr(10), Value: 10}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		change func(b *CatalogBackup)
+		want   []string
+	}{
+		{name: "valid", change: func(b *CatalogBackup) {}},
+		{
+			name:   "negative price",
+			change: func(b *CatalogBackup) { b.Products[0].Price = -1 },
+			want:   []string{"product 10: price is negative"},
+		},
+		{
+			name:   "category not in the backup",
+			change: func(b *CatalogBackup) { b.Products[0].CategoryID = 2 },
+			want:   []string{"product 10: category 2 is not in the backup"},
+		},
+		{
+			name: "promotion targets missing rows",
+			change: func(b *CatalogBackup) {
+				b.Promotions[0].ProductID = intPtr(11)
+				b.Promotions[0].CategoryID = intPtr(3)
+			},
+			want: []string{
+				"promotion 100: product 11 is not in the backup",
+				"promotion 100: category 3 is not in the backup",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := valid()
+			tt.change(b)
+			err := b.Validate()
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+			var e *Error
+			if !errors.As(err, &e) || !errors.Is(e, ErrInvalidBackup) {
+				t.Fatalf("Validate error = %v, want %v", err, ErrInvalidBackup)
+			}
+			if !reflect.DeepEqual(e.Details, tt.want) {
+				t.Errorf("Details = %#v, want %#v", e.Details, tt.want)
+			}
+		})
+	}
+}
@@ -6,5 +6,13 @@ type Category struct {
 	ID					int					`json:"id"`
 	Name				string			`json:"name"`
 	Description	string			`json:"description"`
+	TaxExempt		bool				`json:"tax_exempt"`
 	CreatedAt		*time.Time	`json:"created_at"`
-}
\ No newline at end of file
+}
+
+// CategoryRequest is the body of create and update category.
+type CategoryRequest struct {
+	Name				string	`json:"name" validate:"required,max=255"`
+	Description	string	`json:"description"`
+	TaxExempt		bool		`json:"tax_exempt"`
+}
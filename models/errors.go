@@ -0,0 +1,111 @@
+package models
+
+import "kasir-api/i18n"
+
+// ErrorKind is the broad class of a domain error. It decides the HTTP status
+// the error is reported with.
+type ErrorKind int
+
+const (
+	KindInternal ErrorKind = iota
+	KindValidation
+	KindNotFound
+	KindConflict
+	// KindUnprocessable is a well-formed request that breaks a business
+	// rule, such as paying less than the total.
+	KindUnprocessable
+	KindUnauthorized
+	KindForbidden
+	KindTimeout
+)
+
+// Error is a domain error returned by services. Code is stable and meant for
+// programs, and also keys the message in the i18n catalog; Params fills the
+// placeholders of that message. Details carries extra data such as the
+// products that are out of stock.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Params  map[string]string
+	Details interface{}
+}
+
+// Error returns the message in the default language.
+func (e *Error) Error() string {
+	return e.Message(i18n.Default)
+}
+
+// Message returns the message for people in lang.
+func (e *Error) Message(lang string) string {
+	return i18n.T(lang, e.Code, e.Params)
+}
+
+// Is reports whether target is an *Error with the same code, so
+// errors.Is(err, ErrShiftNotOpen) also holds for a copy carrying details.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithDetails returns a copy of e carrying details.
+func (e *Error) WithDetails(details interface{}) *Error {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
+// LocalizedDetails returns Details with any field error messages in lang.
+func (e *Error) LocalizedDetails(lang string) interface{} {
+	details, ok := e.Details.(ValidationDetails)
+	if !ok {
+		return e.Details
+	}
+	fields := make([]FieldError, len(details.Fields))
+	for i, f := range details.Fields {
+		f.Message = i18n.FieldMessage(lang, f.Field, f.Rule, f.Param)
+		fields[i] = f
+	}
+	return ValidationDetails{Fields: fields}
+}
+
+// NewInvalidIDError is returned when a path parameter is not a valid ID.
+func NewInvalidIDError(param string) *Error {
+	return &Error{
+		Kind:    KindValidation,
+		Code:    "invalid_id",
+		Params:  map[string]string{"param": param},
+		Details: map[string]string{"param": param},
+	}
+}
+
+var (
+	ErrInvalidBody   = &Error{Kind: KindValidation, Code: "invalid_body"}
+	ErrInternal      = &Error{Kind: KindInternal, Code: "internal_error"}
+	ErrTimeout       = &Error{Kind: KindTimeout, Code: "timeout"}
+	ErrUnauthorized  = &Error{Kind: KindUnauthorized, Code: "unauthorized"}
+	ErrForbidden     = &Error{Kind: KindForbidden, Code: "forbidden"}
+	ErrRouteNotFound = &Error{Kind: KindNotFound, Code: "route_not_found"}
+)
+
+// Not found errors, one per entity, returned by services instead of
+// sql.ErrNoRows.
+var (
+	ErrCategoryNotFound    = &Error{Kind: KindNotFound, Code: "category_not_found"}
+	ErrProductNotFound     = &Error{Kind: KindNotFound, Code: "product_not_found"}
+	ErrPromotionNotFound   = &Error{Kind: KindNotFound, Code: "promotion_not_found"}
+	ErrTransactionNotFound = &Error{Kind: KindNotFound, Code: "transaction_not_found"}
+	ErrPrintJobNotFound    = &Error{Kind: KindNotFound, Code: "print_job_not_found"}
+	ErrShiftNotFound       = &Error{Kind: KindNotFound, Code: "shift_not_found"}
+	ErrUserNotFound        = &Error{Kind: KindNotFound, Code: "user_not_found"}
+	ErrTerminalNotFound    = &Error{Kind: KindNotFound, Code: "terminal_not_found"}
+	ErrAPIKeyNotFound      = &Error{Kind: KindNotFound, Code: "api_key_not_found"}
+)
+
+var (
+	// ErrCategoryInUse is returned when deleting a category that still has
+	// products.
+	ErrCategoryInUse = &Error{Kind: KindConflict, Code: "category_in_use"}
+	// ErrInvalidPromotionTarget is returned when a promotion names a product
+	// or category that does not exist.
+	ErrInvalidPromotionTarget = &Error{Kind: KindValidation, Code: "invalid_promotion_target"}
+)
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+var (
+	// ErrIdempotencyKeyMismatch means the key was already used for a checkout
+	// with a different request body.
+	ErrIdempotencyKeyMismatch = &Error{Kind: KindUnprocessable, Code: "idempotency_key_mismatch"}
+	// ErrIdempotencyKeyInProgress means the key is claimed but the original
+	// checkout has not produced a response yet.
+	ErrIdempotencyKeyInProgress = &Error{Kind: KindConflict, Code: "idempotency_key_in_progress"}
+)
+
+// IdempotencyKey is a checkout key as sent by one caller. Scope names that
+// caller, so the same key from another terminal or user is a different key.
+type IdempotencyKey struct {
+	Scope         string          `json:"scope"`
+	Key           string          `json:"key"`
+	RequestHash   string          `json:"request_hash"`
+	TransactionID *int            `json:"transaction_id"`
+	Response      json.RawMessage `json:"response"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
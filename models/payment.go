@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+const (
+	PaymentMethodCash      = "cash"
+	PaymentMethodDebitCard = "debit_card"
+	PaymentMethodQRIS      = "qris"
+	PaymentMethodEWallet   = "ewallet"
+	PaymentMethodTransfer  = "transfer"
+)
+
+func IsValidPaymentMethod(method string) bool {
+	switch method {
+	case PaymentMethodCash, PaymentMethodDebitCard, PaymentMethodQRIS, PaymentMethodEWallet, PaymentMethodTransfer:
+		return true
+	}
+	return false
+}
+
+// ErrNonCashOverpayment is returned when card, QRIS, e-wallet or transfer
+// payments add up to more than the transaction total. Only cash gives change.
+var ErrNonCashOverpayment = &Error{Kind: KindUnprocessable, Code: "non_cash_overpayment"}
+
+// ErrInsufficientPayment is returned when the payments do not cover the
+// transaction total; see NewInsufficientPaymentError.
+var ErrInsufficientPayment = &Error{Kind: KindUnprocessable, Code: "insufficient_payment"}
+
+// NewInsufficientPaymentError returns ErrInsufficientPayment with the total
+// and the amount paid.
+func NewInsufficientPaymentError(total, paid int) *Error {
+	return ErrInsufficientPayment.WithDetails(map[string]int{"total_amount": total, "paid_amount": paid})
+}
+
+type CheckoutPayment struct {
+	Method    string `json:"method" validate:"oneof=cash debit_card qris ewallet transfer"`
+	Amount    int    `json:"amount" validate:"gt=0"`
+	Reference string `json:"reference,omitempty"`
+}
+
+// TransactionPayment is one payment applied to a transaction. Tendered is what
+// the customer handed over; Amount is the part applied to the total, so for
+// cash the difference is the change given back.
+type TransactionPayment struct {
+	ID            int       `json:"id"`
+	TransactionID int       `json:"transaction_id"`
+	Method        string    `json:"method"`
+	Amount        int       `json:"amount"`
+	Tendered      int       `json:"tendered"`
+	Reference     string    `json:"reference,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type PaymentMethodSummary struct {
+	Method         string `json:"method"`
+	TotalAmount    int    `json:"total_amount"`
+	TotalTransaksi int    `json:"total_transaksi"`
+}
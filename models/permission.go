@@ -0,0 +1,68 @@
+package models
+
+// Permissions guard groups of routes. Users get the permissions of their
+// role; terminals get the ones granted to them.
+const (
+	PermissionCatalogRead        = "catalog:read"
+	PermissionCatalogWrite       = "catalog:write"
+	PermissionCheckout           = "checkout"
+	PermissionShifts             = "shifts"
+	PermissionTransactionsRead   = "transactions:read"
+	PermissionTransactionsCancel = "transactions:cancel"
+	PermissionPrint              = "print"
+	PermissionReports            = "reports"
+	PermissionTerminals          = "terminals"
+	PermissionUsers              = "users"
+	PermissionAudit              = "audit"
+)
+
+var cashierPermissions = []string{
+	PermissionCatalogRead,
+	PermissionCheckout,
+	PermissionShifts,
+	PermissionTransactionsRead,
+	PermissionPrint,
+}
+
+var adminPermissions = append(append([]string{}, cashierPermissions...),
+	PermissionCatalogWrite,
+	PermissionTransactionsCancel,
+	PermissionTerminals,
+)
+
+var ownerPermissions = append(append([]string{}, adminPermissions...),
+	PermissionReports,
+	PermissionUsers,
+	PermissionAudit,
+)
+
+// RolePermissions returns the permissions granted to a user role.
+func RolePermissions(role string) []string {
+	switch role {
+	case RoleOwner:
+		return ownerPermissions
+	case RoleAdmin:
+		return adminPermissions
+	case RoleCashier:
+		return cashierPermissions
+	}
+	return nil
+}
+
+// DefaultTerminalPermissions is what a terminal registered without an
+// explicit list may do: the same as a cashier.
+func DefaultTerminalPermissions() []string {
+	return append([]string{}, cashierPermissions...)
+}
+
+// IsGrantableToTerminal reports whether permission may be given to a
+// terminal. Managing users and terminals, reading reports and reading the
+// audit log always needs a person.
+func IsGrantableToTerminal(permission string) bool {
+	switch permission {
+	case PermissionCatalogRead, PermissionCatalogWrite, PermissionCheckout, PermissionShifts,
+		PermissionTransactionsRead, PermissionTransactionsCancel, PermissionPrint:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+const (
+	PrintJobStatusQueued   = "queued"
+	PrintJobStatusPrinting = "printing"
+	PrintJobStatusDone     = "done"
+	PrintJobStatusFailed   = "failed"
+)
+
+// PrintJob is a receipt waiting to be, or already, sent to a network printer.
+// Failed attempts are retried until the attempt limit, after which the job
+// stays failed.
+type PrintJob struct {
+	ID             int        `json:"id"`
+	TransactionID  int        `json:"transaction_id"`
+	PrinterAddress string     `json:"printer_address"`
+	PaperWidth     int        `json:"paper_width"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	LastError      string     `json:"last_error,omitempty"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	PrintedAt      *time.Time `json:"printed_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+type PrintRequest struct {
+	PrinterAddress string `json:"printer_address"`
+	PaperWidth     int    `json:"paper_width" validate:"omitempty,oneof=58 80"`
+}
+
+// ErrNoPrinter is returned when printing without a printer address and no
+// default printer is configured.
+var ErrNoPrinter = &Error{Kind: KindValidation, Code: "no_printer"}
+
+// ErrPrinterNotAllowed is returned when printing to an address that is
+// neither the default printer nor one of the allowed printers.
+var ErrPrinterNotAllowed = &Error{Kind: KindValidation, Code: "printer_not_allowed"}
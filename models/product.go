@@ -6,8 +6,74 @@ type Product struct {
 	ID						int					`json:"id"`
 	CategoryID		int					`json:"category_id"`
 	CategoryName	string			`json:"category_name"`
+	SKU						string			`json:"sku"`
+	Barcodes			[]string		`json:"barcodes"`
+	PLU						int					`json:"plu"`
 	Name					string			`json:"name"`
 	Price					int					`json:"price"`
+	Cost					int					`json:"cost"`
 	Stock					int					`json:"stock"`
+	TaxExempt			bool				`json:"tax_exempt"`
 	CreatedAt			*time.Time	`json:"created_at"`
 }
+
+// ProductRequest is the body of create and update product. Stock may be 0
+// to register a product that is out of stock. PLU is the number scales
+// print on weighed items. On update, a SKU, Barcodes or PLU left out keeps
+// the current one; an empty SKU or list, or a PLU of 0, clears it.
+type ProductRequest struct {
+	CategoryID	int			`json:"category_id" validate:"required,gt=0"`
+	SKU					*string		`json:"sku" validate:"omitempty,max=64"`
+	Barcodes		[]string	`json:"barcodes" validate:"max=20,unique,dive,required,max=64"`
+	PLU					*int			`json:"plu" validate:"omitempty,gte=0"`
+	Name				string	`json:"name" validate:"required,max=255"`
+	Price				int			`json:"price" validate:"required,gt=0"`
+	Cost				int			`json:"cost" validate:"gte=0"`
+	Stock				int			`json:"stock" validate:"gte=0"`
+	TaxExempt		bool		`json:"tax_exempt"`
+}
+
+// ErrProductCategoryNotFound is returned when a product names a category
+// that does not exist.
+var ErrProductCategoryNotFound = NewInvalidFieldError("category_id", "exists", "")
+
+var (
+	// ErrSKUTaken is returned when another product already has the SKU.
+	ErrSKUTaken = &Error{Kind: KindConflict, Code: "sku_taken"}
+	// ErrBarcodeTaken is returned when another product already has one of
+	// the barcodes; see NewBarcodeTakenError.
+	ErrBarcodeTaken = &Error{Kind: KindConflict, Code: "barcode_taken"}
+	// ErrPLUTaken is returned when another product already has the PLU.
+	ErrPLUTaken = &Error{Kind: KindConflict, Code: "plu_taken"}
+	// ErrInvalidBarcode is returned for a scale label with a wrong check
+	// digit or an empty value; see NewInvalidBarcodeError.
+	ErrInvalidBarcode = &Error{Kind: KindValidation, Code: "invalid_barcode"}
+)
+
+// NewBarcodeTakenError returns ErrBarcodeTaken naming the barcode.
+func NewBarcodeTakenError(barcode string) *Error {
+	return ErrBarcodeTaken.WithDetails(map[string]string{"barcode": barcode})
+}
+
+// NewInvalidBarcodeError returns ErrInvalidBarcode naming the barcode.
+func NewInvalidBarcodeError(barcode string) *Error {
+	return ErrInvalidBarcode.WithDetails(map[string]string{"barcode": barcode})
+}
+
+// ScannedProduct is the product found for a scanned barcode. For a scale
+// label, Scale holds what the label encodes.
+type ScannedProduct struct {
+	Product
+	Scale *ScaleLabel `json:"scale,omitempty"`
+}
+
+// ScaleLabel is a price, weight or count label printed in store. Quantity
+// and UnitPrice are what checkout charges for one scan of the label.
+// WeightGrams is only set for weight labels.
+type ScaleLabel struct {
+	PLU         int `json:"plu"`
+	WeightGrams int `json:"weight_grams,omitempty"`
+	Quantity    int `json:"quantity"`
+	UnitPrice   int `json:"unit_price"`
+	Subtotal    int `json:"subtotal"`
+}
@@ -0,0 +1,128 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	ProductSortName      = "name"
+	ProductSortPrice     = "price"
+	ProductSortStock     = "stock"
+	ProductSortCreatedAt = "created_at"
+
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+func IsValidProductSort(sort string) bool {
+	switch sort {
+	case ProductSortName, ProductSortPrice, ProductSortStock, ProductSortCreatedAt:
+		return true
+	}
+	return false
+}
+
+// ProductFilter selects a page of products. A page is either Limit products
+// from Offset, or, when After is set, the Limit products that follow the
+// cursor. InStock true keeps products with stock, false those without.
+type ProductFilter struct {
+	Name       string
+	CategoryID int
+	MinPrice   *int
+	MaxPrice   *int
+	InStock    *bool
+	Sort       string
+	Order      string
+	Limit      int
+	Offset     int
+	After      *ProductCursor
+}
+
+// ProductPagination describes a page of products. NextCursor fetches the
+// page after this one and is empty on the last page.
+type ProductPagination struct {
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	Total      int    `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ProductCursor is the position of the last product of a page: its sort
+// value and ID, and the order the page was sorted in. Clients get it as an
+// opaque string.
+type ProductCursor struct {
+	Sort  string      `json:"s"`
+	Order string      `json:"o"`
+	Value interface{} `json:"v"`
+	ID    int         `json:"id"`
+}
+
+var errInvalidCursor = errors.New("invalid product cursor")
+
+// NewProductCursor returns the cursor pointing after p in a list sorted by
+// sort and order.
+func NewProductCursor(sort, order string, p Product) *ProductCursor {
+	cursor := &ProductCursor{Sort: sort, Order: order, ID: p.ID}
+	switch sort {
+	case ProductSortPrice:
+		cursor.Value = p.Price
+	case ProductSortStock:
+		cursor.Value = p.Stock
+	case ProductSortCreatedAt:
+		if p.CreatedAt != nil {
+			cursor.Value = p.CreatedAt.Format(time.RFC3339Nano)
+		}
+	default:
+		cursor.Value = p.Name
+	}
+	return cursor
+}
+
+// Encode returns the cursor as a URL-safe string.
+func (c *ProductCursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeProductCursor parses a cursor made by Encode, restoring Value to the
+// type of the sort column.
+func DecodeProductCursor(s string) (*ProductCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	var cursor ProductCursor
+	if err := json.Unmarshal(b, &cursor); err != nil {
+		return nil, errInvalidCursor
+	}
+	if !IsValidProductSort(cursor.Sort) || (cursor.Order != SortAsc && cursor.Order != SortDesc) {
+		return nil, errInvalidCursor
+	}
+
+	switch cursor.Sort {
+	case ProductSortPrice, ProductSortStock:
+		n, ok := cursor.Value.(float64)
+		if !ok {
+			return nil, errInvalidCursor
+		}
+		cursor.Value = int(n)
+	case ProductSortCreatedAt:
+		v, ok := cursor.Value.(string)
+		if !ok {
+			return nil, errInvalidCursor
+		}
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return nil, errInvalidCursor
+		}
+		cursor.Value = t
+	default:
+		if _, ok := cursor.Value.(string); !ok {
+			return nil, errInvalidCursor
+		}
+	}
+	return &cursor, nil
+}
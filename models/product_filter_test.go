@@ -0,0 +1,70 @@
+package models
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func TestProductCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2024, 3, 5, 14, 30, 15, 123456789, time.UTC)
+	p := Product{ID: 42, Name: "Kopi 100% Arabika_Gayo", Price: 35000, Stock: 7, CreatedAt: &createdAt}
+
+	tests := []struct {
+		sort string
+		want interface{}
+	}{
+		{sort: ProductSortName, want: p.Name},
+		{sort: ProductSortPrice, want: p.Price},
+		{sort: ProductSortStock, want: p.Stock},
+		{sort: ProductSortCreatedAt, want: createdAt},
+	}
+	for _, tt := range tests {
+		for _, order := range []string{SortAsc, SortDesc} {
+			t.Run(tt.sort+" "+order, func(t *testing.T) {
+				got, err := DecodeProductCursor(NewProductCursor(tt.sort, order, p).Encode())
+				if err != nil {
+					t.Fatalf("DecodeProductCursor: %v", err)
+				}
+				if got.Sort != tt.sort || got.Order != order || got.ID != p.ID {
+					t.Errorf("cursor = %+v, want sort %s, order %s and ID %d", got, tt.sort, order, p.ID)
+				}
+				if want, ok := tt.want.(time.Time); ok {
+					if v, ok := got.Value.(time.Time); !ok || !v.Equal(want) {
+						t.Errorf("Value = %#v, want %v", got.Value, want)
+					}
+					return
+				}
+				if got.Value != tt.want {
+					t.Errorf("Value = %#v, want %#v", got.Value, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestDecodeProductCursorInvalid(t *testing.T) {
+	encode := func(s string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	}
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "!!!"},
+		{name: "not JSON", cursor: encode("cursor")},
+		{name: "unknown sort", cursor: encode(`{"s":"cost","o":"asc","v":1,"id":1}`)},
+		{name: "unknown order", cursor: encode(`{"s":"name","o":"up","v":"a","id":1}`)},
+		{name: "name not a string", cursor: encode(`{"s":"name","o":"asc","v":1,"id":1}`)},
+		{name: "price not a number", cursor: encode(`{"s":"price","o":"asc","v":"1","id":1}`)},
+		{name: "stock missing", cursor: encode(`{"s":"stock","o":"desc","id":1}`)},
+		{name: "created_at not a time", cursor: encode(`{"s":"created_at","o":"asc","v":"yesterday","id":1}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if c, err := DecodeProductCursor(tt.cursor); err == nil {
+				t.Errorf("DecodeProductCursor = %+v, want an error", c)
+			}
+		})
+	}
+}
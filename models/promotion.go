@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+const (
+	PromotionTypePercentage = "percentage"
+	PromotionTypeFixed      = "fixed"
+	PromotionTypeBuyXGetY   = "buy_x_get_y"
+
+	PromotionScopeProduct  = "product"
+	PromotionScopeCategory = "category"
+	PromotionScopeCart     = "cart"
+)
+
+// Promotion is a discount rule applied automatically at checkout.
+//
+// Value is a percentage for the percentage type and rupiah for the fixed type;
+// a fixed discount is taken per unit for product and category scope and once
+// for cart scope. Buy X get Y gives GetQty units free for every BuyQty+GetQty
+// units of the same product. MinSpend and MaxDiscount are ignored when 0.
+type Promotion struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Type        string     `json:"type"`
+	Scope       string     `json:"scope"`
+	ProductID   *int       `json:"product_id"`
+	CategoryID  *int       `json:"category_id"`
+	Value       int        `json:"value"`
+	BuyQty      int        `json:"buy_qty"`
+	GetQty      int        `json:"get_qty"`
+	MinSpend    int        `json:"min_spend"`
+	MaxDiscount int        `json:"max_discount"`
+	StartsAt    *time.Time `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at"`
+	Priority    int        `json:"priority"`
+	Stackable   bool       `json:"stackable"`
+	Active      bool       `json:"active"`
+	CreatedAt   *time.Time `json:"created_at"`
+}
+
+type AppliedPromotion struct {
+	PromotionID *int   `json:"promotion_id"`
+	Name        string `json:"name"`
+	Amount      int    `json:"amount"`
+}
@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// ErrReturnDetailNotFound is returned when a return line references a
+// transaction detail that does not belong to the transaction.
+var ErrReturnDetailNotFound = &Error{Kind: KindValidation, Code: "return_detail_not_found"}
+
+// Return is a partial return of a completed transaction, or with FullRefund
+// what was left of it when the whole sale was refunded. TotalAmount is the
+// amount given back to the customer and is netted out of report revenue.
+// A cash refund is paid from the drawer of ShiftID.
+type Return struct {
+	ID            int            `json:"id"`
+	TransactionID int            `json:"transaction_id"`
+	TotalAmount   int            `json:"total_amount"`
+	FullRefund    bool           `json:"full_refund"`
+	RefundMethod  string         `json:"refund_method"`
+	ShiftID       *int           `json:"shift_id"`
+	Reason        string         `json:"reason"`
+	PerformedBy   string         `json:"performed_by"`
+	CreatedAt     time.Time      `json:"created_at"`
+	Details       []ReturnDetail `json:"details"`
+}
+
+type ReturnDetail struct {
+	ID                  int    `json:"id"`
+	ReturnID            int    `json:"return_id"`
+	TransactionDetailID int    `json:"transaction_detail_id"`
+	ProductID           int    `json:"product_id"`
+	ProductName         string `json:"product_name,omitempty"`
+	Quantity            int    `json:"quantity"`
+	Amount              int    `json:"amount"`
+	TaxAmount           int    `json:"tax_amount"`
+}
+
+type ReturnItem struct {
+	TransactionDetailID int `json:"transaction_detail_id" validate:"required,gt=0"`
+	Quantity            int `json:"quantity" validate:"gt=0"`
+}
+
+// ReturnRequest.PerformedBy is set from the caller; a value in the body is
+// ignored. RefundMethod defaults to cash. A cash refund comes out of an open
+// shift's drawer, picked by ShiftID as in checkout.
+type ReturnRequest struct {
+	Items        []ReturnItem `json:"items" validate:"min=1,dive"`
+	Reason       string       `json:"reason" validate:"required"`
+	PerformedBy  string       `json:"performed_by" validate:"required"`
+	RefundMethod string       `json:"refund_method" validate:"omitempty,oneof=cash debit_card qris ewallet transfer"`
+	ShiftID      int          `json:"shift_id,omitempty" validate:"gte=0"`
+}
+
+type ReturnExcess struct {
+	TransactionDetailID int    `json:"transaction_detail_id"`
+	ProductName         string `json:"product_name"`
+	Requested           int    `json:"requested"`
+	Returnable          int    `json:"returnable"`
+}
+
+// ErrReturnQuantityExceeded is returned when a return asks for more than was
+// sold minus what has already been returned on one or more lines; see
+// NewReturnQuantityError.
+var ErrReturnQuantityExceeded = &Error{Kind: KindUnprocessable, Code: "return_quantity_exceeded"}
+
+// NewReturnQuantityError returns ErrReturnQuantityExceeded listing the lines
+// that ask for too much.
+func NewReturnQuantityError(items []ReturnExcess) *Error {
+	return ErrReturnQuantityExceeded.WithDetails(map[string]interface{}{"items": items})
+}
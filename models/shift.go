@@ -0,0 +1,85 @@
+package models
+
+import "time"
+
+const (
+	ShiftStatusOpen   = "open"
+	ShiftStatusClosed = "closed"
+
+	CashMovementPayIn  = "pay_in"
+	CashMovementPayOut = "pay_out"
+)
+
+var (
+	// ErrShiftNotOpen is returned when selling on, moving cash in or out of,
+	// or closing a shift that is already closed.
+	ErrShiftNotOpen = &Error{Kind: KindConflict, Code: "shift_not_open"}
+	// ErrNoOpenShift is returned by checkout when no shift is open and the
+	// store requires one.
+	ErrNoOpenShift = &Error{Kind: KindConflict, Code: "no_open_shift"}
+	// ErrShiftRequired is returned by checkout when several shifts are open
+	// and the request does not say which one the sale belongs to.
+	ErrShiftRequired = &Error{Kind: KindValidation, Code: "shift_required"}
+	// ErrShiftAlreadyOpen is returned when the cashier already has an open shift.
+	ErrShiftAlreadyOpen = &Error{Kind: KindConflict, Code: "shift_already_open"}
+)
+
+// Shift is one cashier's session at the register. ExpectedCash and
+// CountedCash are set when the shift is closed.
+type Shift struct {
+	ID           int        `json:"id"`
+	CashierName  string     `json:"cashier_name"`
+	Status       string     `json:"status"`
+	OpeningCash  int        `json:"opening_cash"`
+	ExpectedCash *int       `json:"expected_cash"`
+	CountedCash  *int       `json:"counted_cash"`
+	CloseNote    string     `json:"close_note,omitempty"`
+	OpenedAt     time.Time  `json:"opened_at"`
+	ClosedAt     *time.Time `json:"closed_at"`
+}
+
+type CashMovement struct {
+	ID        int       `json:"id"`
+	ShiftID   int       `json:"shift_id"`
+	Type      string    `json:"type" validate:"oneof=pay_in pay_out"`
+	Amount    int       `json:"amount" validate:"gt=0"`
+	Reason    string    `json:"reason" validate:"required"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OpenShiftRequest.CashierName is set from the caller; a value in the body
+// is ignored.
+type OpenShiftRequest struct {
+	CashierName string `json:"cashier_name" validate:"required"`
+	OpeningCash int    `json:"opening_cash" validate:"gte=0"`
+}
+
+type CloseShiftRequest struct {
+	CountedCash *int   `json:"counted_cash" validate:"required,gte=0"`
+	Note        string `json:"note"`
+}
+
+// ShiftSummary is the X report of an open shift or the Z report of a closed
+// one. Voided sales are counted separately and left out of sales and
+// expected cash. CashRefunds is the cash paid back for returns and refunds
+// from this shift's drawer, and is taken out of expected cash; a refunded
+// sale stays in the sales of the shift it was rung up on.
+type ShiftSummary struct {
+	Shift            Shift                  `json:"shift"`
+	TotalTransaksi   int                    `json:"total_transaksi"`
+	TotalBatal       int                    `json:"total_batal"`
+	GrossSales       int                    `json:"gross_sales"`
+	TotalDiskon      int                    `json:"total_diskon"`
+	TotalPajak       int                    `json:"total_pajak"`
+	TotalService     int                    `json:"total_service_charge"`
+	TotalSales       int                    `json:"total_sales"`
+	MetodePembayaran []PaymentMethodSummary `json:"metode_pembayaran"`
+	CashSales        int                    `json:"cash_sales"`
+	PayIn            int                    `json:"pay_in"`
+	PayOut           int                    `json:"pay_out"`
+	CashRefunds      int                    `json:"cash_refunds"`
+	ExpectedCash     int                    `json:"expected_cash"`
+	CountedCash      *int                   `json:"counted_cash"`
+	Difference       *int                   `json:"difference"`
+	CashMovements    []CashMovement         `json:"cash_movements"`
+}
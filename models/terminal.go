@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// ErrInvalidAPIKey is returned for a terminal key that is unknown, revoked
+// or belongs to a deactivated terminal.
+var ErrInvalidAPIKey = &Error{Kind: KindUnauthorized, Code: "invalid_api_key"}
+
+// Terminal is a registered POS device that authenticates with an API key
+// instead of a user password.
+type Terminal struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Permissions []string  `json:"permissions"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TerminalAPIKey describes an issued key. Only a short prefix is kept in
+// the clear so a key can be recognised in listings.
+type TerminalAPIKey struct {
+	ID         int        `json:"id"`
+	TerminalID int        `json:"terminal_id"`
+	Prefix     string     `json:"prefix"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+// IssuedAPIKey is returned once, when the key is created; Key cannot be
+// retrieved again.
+type IssuedAPIKey struct {
+	TerminalAPIKey
+	Key string `json:"key"`
+}
+
+type TerminalRequest struct {
+	Name        string   `json:"name" validate:"required"`
+	Permissions []string `json:"permissions" validate:"dive,terminal_permission"`
+	Active      *bool    `json:"active"`
+}
@@ -1,32 +1,138 @@
 package models
 
-import (
-	"time"
+import "time"
+
+const (
+	TransactionStatusCompleted = "completed"
+	TransactionStatusVoided    = "voided"
+	TransactionStatusRefunded  = "refunded"
+)
+
+// ErrTransactionNotCompleted is returned when voiding or refunding a
+// transaction that has already been voided or refunded.
+var ErrTransactionNotCompleted = &Error{Kind: KindConflict, Code: "transaction_not_completed"}
+
+var (
+	// ErrTransactionHasReturns is returned when voiding a sale that already
+	// has returns; it can only be refunded.
+	ErrTransactionHasReturns = &Error{Kind: KindConflict, Code: "transaction_has_returns"}
+	// ErrVoidShiftClosed is returned when voiding a sale whose shift has been
+	// closed, as its cash has already been counted; it can only be refunded.
+	ErrVoidShiftClosed = &Error{Kind: KindConflict, Code: "void_shift_closed"}
 )
 
 type Transaction struct {
-	ID          int                 `json:"id"`
-	TotalAmount int                 `json:"total_amount"`
-	CreatedAt   time.Time           `json:"created_at"`
-	Details     []TransactionDetail `json:"details"`
+	ID             int                  `json:"id"`
+	GrossAmount    int                  `json:"gross_amount"`
+	DiscountAmount int                  `json:"discount_amount"`
+	ServiceCharge  int                  `json:"service_charge"`
+	TaxAmount      int                  `json:"tax_amount"`
+	TaxInclusive   bool                 `json:"tax_inclusive"`
+	TotalAmount    int                  `json:"total_amount"`
+	PaidAmount     int                  `json:"paid_amount"`
+	ChangeAmount   int                  `json:"change_amount"`
+	ShiftID        *int                 `json:"shift_id"`
+	TerminalID     *int                 `json:"terminal_id"`
+	Status         string               `json:"status"`
+	CancelledAt    *time.Time           `json:"cancelled_at,omitempty"`
+	CancelledBy    string               `json:"cancelled_by,omitempty"`
+	CancelReason   string               `json:"cancel_reason,omitempty"`
+	CreatedAt      time.Time            `json:"created_at"`
+	Details        []TransactionDetail  `json:"details,omitempty"`
+	Payments       []TransactionPayment `json:"payments,omitempty"`
+	Promotions     []AppliedPromotion   `json:"promotions,omitempty"`
+}
+
+// TransactionFilter narrows the transaction listing. Zero values mean the
+// filter is not applied.
+type TransactionFilter struct {
+	StartDate     string
+	EndDate       string
+	MinAmount     *int
+	MaxAmount     *int
+	ProductID     int
+	PaymentMethod string
+	Status        string
+	ShiftID       int
+	TerminalID    int
+	Page          int
+	Limit         int
+}
+
+type Pagination struct {
+	Page  int `json:"page"`
+	Limit int `json:"limit"`
+	Total int `json:"total"`
 }
 
+// TransactionDetail keeps a snapshot of the product as it was sold, so later
+// renames, repricing or deletion of the product do not change the receipt.
+// ProductID is 0 once the product has been deleted. Subtotal is the line
+// total after Discount; Total adds the service charge and, unless prices are
+// tax inclusive, the tax. TaxRate is in basis points and TaxBase is the DPP.
 type TransactionDetail struct {
 	ID            int    `json:"id"`
 	TransactionID int    `json:"transaction_id"`
 	ProductID     int    `json:"product_id"`
 	ProductName   string `json:"product_name,omitempty"`
+	CategoryID    *int   `json:"category_id"`
+	CategoryName  string `json:"category_name"`
+	UnitPrice     int    `json:"unit_price"`
+	UnitCost      int    `json:"unit_cost"`
 	Quantity      int    `json:"quantity"`
+	Discount      int    `json:"discount"`
 	Subtotal      int    `json:"subtotal"`
+	ServiceCharge int    `json:"service_charge"`
+	TaxRate       int    `json:"tax_rate"`
+	TaxBase       int    `json:"tax_base"`
+	TaxAmount     int    `json:"tax_amount"`
+	Total         int    `json:"total"`
 }
 
+// CheckoutItem names the product either by ID or by a barcode as read by
+// the scanner, but not both. The barcode may be a scale label, in which case
+// Quantity counts labels and the label decides the price or the units.
 type CheckoutItem struct {
-	ProductID int `json:"product_id"`
-	Quantity  int `json:"quantity"`
+	ProductID int    `json:"product_id,omitempty" validate:"gte=0"`
+	Barcode   string `json:"barcode,omitempty" validate:"omitempty,max=64"`
+	Quantity  int    `json:"quantity" validate:"gt=0"`
 }
 
+// CheckoutRequest.ShiftID picks the shift the sale is rung up on. It can be
+// left out while only one shift is open. Payments are required; a sale is
+// never taken as paid without them.
 type CheckoutRequest struct {
-	Items []CheckoutItem `json:"items"`
+	Items    []CheckoutItem    `json:"items" validate:"min=1,dive"`
+	Payments []CheckoutPayment `json:"payments" validate:"min=1,dive"`
+	ShiftID  int               `json:"shift_id,omitempty" validate:"gte=0"`
+}
+
+// CancelTransactionRequest.PerformedBy is set from the caller; a value in
+// the body is ignored. RefundMethod and ShiftID are used by refunds only and
+// work as in ReturnRequest.
+type CancelTransactionRequest struct {
+	PerformedBy  string `json:"performed_by" validate:"required"`
+	Reason       string `json:"reason" validate:"required"`
+	RefundMethod string `json:"refund_method" validate:"omitempty,oneof=cash debit_card qris ewallet transfer"`
+	ShiftID      int    `json:"shift_id,omitempty" validate:"gte=0"`
+}
+
+type StockShortage struct {
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Requested   int    `json:"requested"`
+	Available   int    `json:"available"`
+}
+
+// ErrInsufficientStock is returned by checkout when one or more cart lines
+// ask for more than the product has in stock; see NewInsufficientStockError.
+// Nothing is written when it occurs.
+var ErrInsufficientStock = &Error{Kind: KindConflict, Code: "insufficient_stock"}
+
+// NewInsufficientStockError returns ErrInsufficientStock listing the short
+// products.
+func NewInsufficientStockError(items []StockShortage) *Error {
+	return ErrInsufficientStock.WithDetails(map[string]interface{}{"items": items})
 }
 
 type BestSellProduct struct {
@@ -34,8 +140,100 @@ type BestSellProduct struct {
 	QtyTerjual int    `json:"qty_terjual"`
 }
 
+// CancelledTransaction is a voided or refunded sale listed in a report. A
+// voided sale is not part of the report revenue; a refunded one is, and its
+// refund is netted out in TotalRefund.
+type CancelledTransaction struct {
+	ID           int       `json:"id"`
+	Status       string    `json:"status"`
+	TotalAmount  int       `json:"total_amount"`
+	CancelledAt  time.Time `json:"cancelled_at"`
+	CancelledBy  string    `json:"cancelled_by"`
+	CancelReason string    `json:"cancel_reason"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TransactionReport.TotalRevenue is net of partial returns and refunds,
+// whose sums are reported separately in TotalRetur and TotalRefund.
 type TransactionReport struct {
-	TotalRevenue   int              `json:"total_revenue"`
-	TotalTransaksi int              `json:"total_transaksi"`
-	ProdukTerlaris BestSellProduct	`json:"produk_terlaris"`
+	TotalRevenue     int                    `json:"total_revenue"`
+	TotalTransaksi   int                    `json:"total_transaksi"`
+	TotalRetur       int                    `json:"total_retur"`
+	TotalRefund      int                    `json:"total_refund"`
+	TotalDiskon      int                    `json:"total_diskon"`
+	TotalPajak       int                    `json:"total_pajak"`
+	TotalService     int                    `json:"total_service_charge"`
+	ProdukTerlaris   BestSellProduct        `json:"produk_terlaris"`
+	MetodePembayaran []PaymentMethodSummary `json:"metode_pembayaran"`
+	TransaksiBatal   []CancelledTransaction `json:"transaksi_batal"`
+}
+
+type BestSellingProduct struct {
+	Name         string `json:"name"`
+	QuantitySold int    `json:"quantity_sold"`
+}
+
+type PaymentMethodTotal struct {
+	Method            string `json:"method"`
+	TotalAmount       int    `json:"total_amount"`
+	TotalTransactions int    `json:"total_transactions"`
+}
+
+// TransactionReportV2 is the report served under /api/v2/reports. It holds
+// the same figures as TransactionReport with English field names.
+type TransactionReportV2 struct {
+	TotalRevenue          int                    `json:"total_revenue"`
+	TotalTransactions     int                    `json:"total_transactions"`
+	TotalReturns          int                    `json:"total_returns"`
+	TotalRefunds          int                    `json:"total_refunds"`
+	TotalDiscount         int                    `json:"total_discount"`
+	TotalTax              int                    `json:"total_tax"`
+	TotalServiceCharge    int                    `json:"total_service_charge"`
+	BestSellingProduct    BestSellingProduct     `json:"best_selling_product"`
+	PaymentMethods        []PaymentMethodTotal   `json:"payment_methods"`
+	CancelledTransactions []CancelledTransaction `json:"cancelled_transactions"`
+}
+
+// V2 returns the report in the version 2 shape.
+func (r *TransactionReport) V2() *TransactionReportV2 {
+	methods := make([]PaymentMethodTotal, len(r.MetodePembayaran))
+	for i, m := range r.MetodePembayaran {
+		methods[i] = PaymentMethodTotal{
+			Method:            m.Method,
+			TotalAmount:       m.TotalAmount,
+			TotalTransactions: m.TotalTransaksi,
+		}
+	}
+	return &TransactionReportV2{
+		TotalRevenue:       r.TotalRevenue,
+		TotalTransactions:  r.TotalTransaksi,
+		TotalReturns:       r.TotalRetur,
+		TotalRefunds:       r.TotalRefund,
+		TotalDiscount:      r.TotalDiskon,
+		TotalTax:           r.TotalPajak,
+		TotalServiceCharge: r.TotalService,
+		BestSellingProduct: BestSellingProduct{
+			Name:         r.ProdukTerlaris.Nama,
+			QuantitySold: r.ProdukTerlaris.QtyTerjual,
+		},
+		PaymentMethods:        methods,
+		CancelledTransactions: r.TransaksiBatal,
+	}
+}
+
+// TaxSummaryLine totals sales taxed at one rate (in basis points). Returned
+// tax is already subtracted from TaxAmount.
+type TaxSummaryLine struct {
+	TaxRate       int `json:"tax_rate"`
+	TaxBase       int `json:"tax_base"`
+	TaxAmount     int `json:"tax_amount"`
+	ServiceCharge int `json:"service_charge"`
+	ReturnedTax   int `json:"returned_tax"`
+}
+
+type TaxSummary struct {
+	TotalTaxBase       int              `json:"total_tax_base"`
+	TotalTaxAmount     int              `json:"total_tax_amount"`
+	TotalServiceCharge int              `json:"total_service_charge"`
+	Rates              []TaxSummaryLine `json:"rates"`
 }
@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+const (
+	RoleOwner   = "owner"
+	RoleAdmin   = "admin"
+	RoleCashier = "cashier"
+)
+
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleOwner, RoleAdmin, RoleCashier:
+		return true
+	}
+	return false
+}
+
+var (
+	// ErrInvalidCredentials is returned by login for an unknown username, a
+	// wrong password or a deactivated account, without saying which.
+	ErrInvalidCredentials = &Error{Kind: KindUnauthorized, Code: "invalid_credentials"}
+	// ErrInvalidToken is returned for a refresh token that is unknown,
+	// expired or already used.
+	ErrInvalidToken = &Error{Kind: KindUnauthorized, Code: "invalid_token"}
+	// ErrInvalidAccessToken is returned for a bearer token that does not
+	// verify or has expired.
+	ErrInvalidAccessToken = &Error{Kind: KindUnauthorized, Code: "invalid_access_token"}
+	// ErrUserLoginRequired is returned by endpoints that only make sense for
+	// a user account, when called with a terminal API key.
+	ErrUserLoginRequired = &Error{Kind: KindForbidden, Code: "user_login_required"}
+	// ErrUsernameTaken is returned when creating a user whose username exists.
+	ErrUsernameTaken = &Error{Kind: KindConflict, Code: "username_taken"}
+)
+
+type User struct {
+	ID           int       `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"-"`
+	Role         string    `json:"role"`
+	Active       bool      `json:"active"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// CreateUserRequest.Username cannot contain ':', which is kept for the names
+// terminals act under.
+type CreateUserRequest struct {
+	Username string `json:"username" validate:"required,excludes=:"`
+	Password string `json:"password" validate:"min=8"`
+	Role     string `json:"role" validate:"oneof=owner admin cashier"`
+}
+
+// UpdateUserRequest changes only the fields that are set.
+type UpdateUserRequest struct {
+	Password *string `json:"password" validate:"omitempty,min=8"`
+	Role     *string `json:"role" validate:"omitempty,oneof=owner admin cashier"`
+	Active   *bool   `json:"active"`
+}
+
+type LoginRequest struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// TokenPair is returned by login and refresh. The refresh token can be used
+// once; refreshing returns a new pair.
+type TokenPair struct {
+	AccessToken      string    `json:"access_token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	User             *User     `json:"user"`
+}
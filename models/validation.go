@@ -0,0 +1,43 @@
+package models
+
+import "kasir-api/i18n"
+
+// FieldError is one invalid field in a request. Field is the JSON name of
+// the field, Rule the rule it broke, such as "required" or "gte", and Param
+// the rule's argument, such as the minimum.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// NewFieldError describes a field that broke rule, with its message in the
+// default language.
+func NewFieldError(field, rule, param string) FieldError {
+	return FieldError{
+		Field:   field,
+		Rule:    rule,
+		Param:   param,
+		Message: i18n.FieldMessage(i18n.Default, field, rule, param),
+	}
+}
+
+// ValidationDetails lists every invalid field of a request.
+type ValidationDetails struct {
+	Fields []FieldError `json:"fields"`
+}
+
+// NewFieldErrors is returned when one or more request fields are invalid.
+func NewFieldErrors(fields ...FieldError) *Error {
+	return &Error{
+		Kind:    KindValidation,
+		Code:    "validation_failed",
+		Details: ValidationDetails{Fields: fields},
+	}
+}
+
+// NewInvalidFieldError is returned when a single field is invalid.
+func NewInvalidFieldError(field, rule, param string) *Error {
+	return NewFieldErrors(NewFieldError(field, rule, param))
+}
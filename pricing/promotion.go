@@ -0,0 +1,193 @@
+package pricing
+
+import (
+	"kasir-api/models"
+	"sort"
+)
+
+// Line is a cart line being priced. Discount accumulates the promotions
+// applied to it and never exceeds the line gross.
+type Line struct {
+	ProductID  int
+	CategoryID int
+	UnitPrice  int
+	Quantity   int
+	Discount   int
+}
+
+func (l Line) Gross() int {
+	return l.UnitPrice * l.Quantity
+}
+
+func (l Line) remaining() int {
+	return l.Gross() - l.Discount
+}
+
+// ApplyPromotions applies the promotions to the lines and returns the ones
+// that gave a discount.
+//
+// Promotions are evaluated from the highest priority down. A stackable
+// promotion combines with everything else. A non-stackable one is skipped on
+// lines that already carry a discount, and once applied no later promotion
+// touches those lines. Minimum spend is checked against the cart gross.
+func ApplyPromotions(lines []Line, promotions []models.Promotion) []models.AppliedPromotion {
+	ordered := make([]models.Promotion, len(promotions))
+	copy(ordered, promotions)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].Priority != ordered[j].Priority {
+			return ordered[i].Priority > ordered[j].Priority
+		}
+		return ordered[i].ID < ordered[j].ID
+	})
+
+	gross := 0
+	for _, l := range lines {
+		gross += l.Gross()
+	}
+
+	locked := make([]bool, len(lines))
+	applied := make([]models.AppliedPromotion, 0)
+	for _, promo := range ordered {
+		if promo.MinSpend > 0 && gross < promo.MinSpend {
+			continue
+		}
+
+		eligible := make([]int, 0, len(lines))
+		for i, l := range lines {
+			if locked[i] || l.remaining() <= 0 || !matches(promo, l) {
+				continue
+			}
+			if !promo.Stackable && l.Discount > 0 {
+				continue
+			}
+			eligible = append(eligible, i)
+		}
+		if len(eligible) == 0 {
+			continue
+		}
+
+		amounts := discounts(promo, lines, eligible)
+		total := 0
+		for _, a := range amounts {
+			total += a
+		}
+		if promo.MaxDiscount > 0 && total > promo.MaxDiscount {
+			amounts = distribute(promo.MaxDiscount, amounts)
+			total = promo.MaxDiscount
+		}
+		if total == 0 {
+			continue
+		}
+
+		for k, i := range eligible {
+			lines[i].Discount += amounts[k]
+			if !promo.Stackable {
+				locked[i] = true
+			}
+		}
+
+		promotionID := promo.ID
+		applied = append(applied, models.AppliedPromotion{
+			PromotionID: &promotionID,
+			Name:        promo.Name,
+			Amount:      total,
+		})
+	}
+
+	return applied
+}
+
+func matches(promo models.Promotion, l Line) bool {
+	switch promo.Scope {
+	case models.PromotionScopeProduct:
+		return promo.ProductID != nil && *promo.ProductID == l.ProductID
+	case models.PromotionScopeCategory:
+		return promo.CategoryID != nil && *promo.CategoryID == l.CategoryID
+	case models.PromotionScopeCart:
+		return true
+	}
+	return false
+}
+
+// discounts returns the discount for each eligible line, capped at what is
+// left of the line.
+func discounts(promo models.Promotion, lines []Line, eligible []int) []int {
+	amounts := make([]int, len(eligible))
+
+	switch promo.Type {
+	case models.PromotionTypePercentage:
+		for k, i := range eligible {
+			amounts[k] = lines[i].remaining() * promo.Value / 100
+		}
+
+	case models.PromotionTypeFixed:
+		if promo.Scope == models.PromotionScopeCart {
+			weights := make([]int, len(eligible))
+			total := 0
+			for k, i := range eligible {
+				weights[k] = lines[i].remaining()
+				total += weights[k]
+			}
+			return distribute(min(promo.Value, total), weights)
+		}
+		for k, i := range eligible {
+			amounts[k] = min(promo.Value*lines[i].Quantity, lines[i].remaining())
+		}
+
+	case models.PromotionTypeBuyXGetY:
+		if promo.BuyQty <= 0 || promo.GetQty <= 0 {
+			return amounts
+		}
+		// Units of the same product count together even when the cart
+		// lists them on separate lines.
+		byProduct := make(map[int][]int)
+		order := make([]int, 0)
+		for k, i := range eligible {
+			id := lines[i].ProductID
+			if _, ok := byProduct[id]; !ok {
+				order = append(order, id)
+			}
+			byProduct[id] = append(byProduct[id], k)
+		}
+		for _, id := range order {
+			quantity := 0
+			for _, k := range byProduct[id] {
+				quantity += lines[eligible[k]].Quantity
+			}
+			free := quantity / (promo.BuyQty + promo.GetQty) * promo.GetQty
+			for _, k := range byProduct[id] {
+				l := lines[eligible[k]]
+				units := min(free, l.Quantity)
+				free -= units
+				amounts[k] = min(units*l.UnitPrice, l.remaining())
+			}
+		}
+	}
+
+	return amounts
+}
+
+// distribute splits amount across weights proportionally. Rounding leftovers
+// go to the heaviest weight so the parts always add up to amount.
+func distribute(amount int, weights []int) []int {
+	parts := make([]int, len(weights))
+	total := 0
+	heaviest := 0
+	for k, w := range weights {
+		total += w
+		if w > weights[heaviest] {
+			heaviest = k
+		}
+	}
+	if total == 0 {
+		return parts
+	}
+
+	given := 0
+	for k, w := range weights {
+		parts[k] = amount * w / total
+		given += parts[k]
+	}
+	parts[heaviest] += amount - given
+	return parts
+}
@@ -0,0 +1,192 @@
+package pricing
+
+import (
+	"kasir-api/models"
+	"testing"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestApplyPromotions(t *testing.T) {
+	type applied struct {
+		id     int
+		amount int
+	}
+	tests := []struct {
+		name          string
+		lines         []Line
+		promotions    []models.Promotion
+		wantApplied   []applied
+		wantDiscounts []int
+	}{
+		{
+			name:  "product percentage",
+			lines: []Line{{ProductID: 1, UnitPrice: 10000, Quantity: 2}, {ProductID: 2, UnitPrice: 5000, Quantity: 1}},
+			promotions: []models.Promotion{
+				{ID: 1, Type: models.PromotionTypePercentage, Scope: models.PromotionScopeProduct, ProductID: intPtr(1), Value: 10},
+			},
+			wantApplied:   []applied{{1, 2000}},
+			wantDiscounts: []int{2000, 0},
+		},
+		{
+			name:  "category scope",
+			lines: []Line{{ProductID: 1, CategoryID: 1, UnitPrice: 10000, Quantity: 1}, {ProductID: 2, CategoryID: 2, UnitPrice: 8000, Quantity: 1}},
+			promotions: []models.Promotion{
+				{ID: 1, Type: models.PromotionTypePercentage, Scope: models.PromotionScopeCategory, CategoryID: intPtr(2), Value: 25},
+			},
+			wantApplied:   []applied{{1, 2000}},
+			wantDiscounts: []int{0, 2000},
+		},
+		{
+			name:  "fixed per unit capped at the line",
+			lines: []Line{{ProductID: 1, UnitPrice: 5000, Quantity: 2}},
+			promotions: []models.Promotion{
+				{ID: 1, Type: models.PromotionTypeFixed, Scope: models.PromotionScopeProduct, ProductID: intPtr(1), Value: 6000},
+			},
+			wantApplied:   []applied{{1, 10000}},
+			wantDiscounts: []int{10000},
+		},
+		{
+			name:  "higher priority non-stackable wins",
+			lines: []Line{{ProductID: 1, UnitPrice: 10000, Quantity: 2}},
+			promotions: []models.Promotion{
+				{ID: 1, Type: models.PromotionTypePercentage, Scope: models.PromotionScopeProduct, ProductID: intPtr(1), Value: 20, Priority: 1},
+				{ID: 2, Type: models.PromotionTypePercentage, Scope: models.PromotionScopeProduct, ProductID: intPtr(1), Value: 10, Priority: 5},
+			},
+			wantApplied:   []applied{{2, 2000}},
+			wantDiscounts: []int{2000},
+		},
+		{
+			name:  "equal priority in ID order",
+			lines: []Line{{ProductID: 1, UnitPrice: 10000, Quantity: 1}},
+			promotions: []models.Promotion{
+				{ID: 2, Type: models.PromotionTypePercentage, Scope: models.PromotionScopeCart, Value: 10, Stackable: true},
+				{ID: 1, Type: models.PromotionTypePercentage, Scope: models.PromotionScopeCart, Value: 50, Stackable: true},
+			},
+			wantApplied:   []applied{{1, 5000}, {2, 500}},
+			wantDiscounts: []int{5500},
+		},
+		{
+			name:  "stackable promotions combine on what is left",
+			lines: []Line{{ProductID: 1, UnitPrice: 10000, Quantity: 2}},
+			promotions: []models.Promotion{
+				{ID: 1, Type: models.PromotionTypePercentage, Scope: models.PromotionScopeProduct, ProductID: intPtr(1), Value: 10, Priority: 5, Stackable: true},
+				{ID: 2, Type: models.PromotionTypeFixed, Scope: models.PromotionScopeCart, Value: 1000, Priority: 1, Stackable: true},
+			},
+			wantApplied:   []applied{{1, 2000}, {2, 1000}},
+			wantDiscounts: []int{3000},
+		},
+		{
+			name:  "non-stackable skips discounted lines",
+			lines: []Line{{ProductID: 1, UnitPrice: 10000, Quantity: 2}},
+			promotions: []models.Promotion{
+				{ID: 1, Type: models.PromotionTypePercentage, Scope: models.PromotionScopeProduct, ProductID: intPtr(1), Value: 10, Priority: 5, Stackable: true},
+				{ID: 2, Type: models.PromotionTypePercentage, Scope: models.PromotionScopeCart, Value: 50, Priority: 1},
+			},
+			wantApplied:   []applied{{1, 2000}},
+			wantDiscounts: []int{2000},
+		},
+		{
+			name:  "non-stackable locks its lines",
+			lines: []Line{{ProductID: 1, UnitPrice: 10000, Quantity: 2}},
+			promotions: []models.Promotion{
+				{ID: 1, Type: models.PromotionTypePercentage, Scope: models.PromotionScopeProduct, ProductID: intPtr(1), Value: 10, Priority: 5},
+				{ID: 2, Type: models.PromotionTypeFixed, Scope: models.PromotionScopeCart, Value: 1000, Priority: 1, Stackable: true},
+			},
+			wantApplied:   []applied{{1, 2000}},
+			wantDiscounts: []int{2000},
+		},
+		{
+			name:  "minimum spend not reached",
+			lines: []Line{{ProductID: 1, UnitPrice: 10000, Quantity: 2}},
+			promotions: []models.Promotion{
+				{ID: 1, Type: models.PromotionTypePercentage, Scope: models.PromotionScopeCart, Value: 10, MinSpend: 50000},
+			},
+			wantApplied:   []applied{},
+			wantDiscounts: []int{0},
+		},
+		{
+			name:  "minimum spend reached exactly",
+			lines: []Line{{ProductID: 1, UnitPrice: 10000, Quantity: 2}, {ProductID: 2, UnitPrice: 30000, Quantity: 1}},
+			promotions: []models.Promotion{
+				{ID: 1, Type: models.PromotionTypePercentage, Scope: models.PromotionScopeCart, Value: 10, MinSpend: 50000},
+			},
+			wantApplied:   []applied{{1, 5000}},
+			wantDiscounts: []int{2000, 3000},
+		},
+		{
+			name:  "buy 2 get 1 below the quantity",
+			lines: []Line{{ProductID: 1, UnitPrice: 3000, Quantity: 2}},
+			promotions: []models.Promotion{
+				{ID: 1, Type: models.PromotionTypeBuyXGetY, Scope: models.PromotionScopeProduct, ProductID: intPtr(1), BuyQty: 2, GetQty: 1},
+			},
+			wantApplied:   []applied{},
+			wantDiscounts: []int{0},
+		},
+		{
+			name:  "buy 2 get 1 with leftover units",
+			lines: []Line{{ProductID: 1, UnitPrice: 3000, Quantity: 5}},
+			promotions: []models.Promotion{
+				{ID: 1, Type: models.PromotionTypeBuyXGetY, Scope: models.PromotionScopeProduct, ProductID: intPtr(1), BuyQty: 2, GetQty: 1},
+			},
+			wantApplied:   []applied{{1, 3000}},
+			wantDiscounts: []int{3000},
+		},
+		{
+			name:  "buy 2 get 1 across lines of the same product",
+			lines: []Line{{ProductID: 1, UnitPrice: 3000, Quantity: 2}, {ProductID: 1, UnitPrice: 3000, Quantity: 4}},
+			promotions: []models.Promotion{
+				{ID: 1, Type: models.PromotionTypeBuyXGetY, Scope: models.PromotionScopeProduct, ProductID: intPtr(1), BuyQty: 2, GetQty: 1},
+			},
+			wantApplied:   []applied{{1, 6000}},
+			wantDiscounts: []int{6000, 0},
+		},
+		{
+			name:  "maximum discount spread over the lines",
+			lines: []Line{{ProductID: 1, UnitPrice: 10000, Quantity: 1}, {ProductID: 2, UnitPrice: 5000, Quantity: 1}},
+			promotions: []models.Promotion{
+				{ID: 1, Type: models.PromotionTypePercentage, Scope: models.PromotionScopeCart, Value: 50, MaxDiscount: 3000},
+			},
+			wantApplied:   []applied{{1, 3000}},
+			wantDiscounts: []int{2000, 1000},
+		},
+		{
+			name:  "percentage rounds down",
+			lines: []Line{{ProductID: 1, UnitPrice: 999, Quantity: 1}},
+			promotions: []models.Promotion{
+				{ID: 1, Type: models.PromotionTypePercentage, Scope: models.PromotionScopeCart, Value: 15},
+			},
+			wantApplied:   []applied{{1, 149}},
+			wantDiscounts: []int{149},
+		},
+		{
+			name:  "fixed cart discount leftover goes to the heaviest line",
+			lines: []Line{{ProductID: 1, UnitPrice: 3333, Quantity: 1}, {ProductID: 2, UnitPrice: 3333, Quantity: 1}, {ProductID: 3, UnitPrice: 3333, Quantity: 1}},
+			promotions: []models.Promotion{
+				{ID: 1, Type: models.PromotionTypeFixed, Scope: models.PromotionScopeCart, Value: 1000},
+			},
+			wantApplied:   []applied{{1, 1000}},
+			wantDiscounts: []int{334, 333, 333},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ApplyPromotions(tt.lines, tt.promotions)
+			if len(got) != len(tt.wantApplied) {
+				t.Fatalf("ApplyPromotions applied %+v, want %+v", got, tt.wantApplied)
+			}
+			for i, want := range tt.wantApplied {
+				if *got[i].PromotionID != want.id || got[i].Amount != want.amount {
+					t.Errorf("applied[%d] = promotion %d for %d, want promotion %d for %d", i, *got[i].PromotionID, got[i].Amount, want.id, want.amount)
+				}
+			}
+			for i, want := range tt.wantDiscounts {
+				if tt.lines[i].Discount != want {
+					t.Errorf("lines[%d].Discount = %d, want %d", i, tt.lines[i].Discount, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package pricing
+
+import "math"
+
+// TaxConfig describes how tax and service charge are added to a sale. Rates
+// are in basis points, so PPN 11% is 1100.
+type TaxConfig struct {
+	RateBps          int
+	Inclusive        bool
+	ServiceChargeBps int
+}
+
+// NewTaxConfig builds a TaxConfig from percentages as they appear in the
+// configuration.
+func NewTaxConfig(ratePercent float64, inclusive bool, serviceChargePercent float64) TaxConfig {
+	return TaxConfig{
+		RateBps:          int(math.Round(ratePercent * 100)),
+		Inclusive:        inclusive,
+		ServiceChargeBps: int(math.Round(serviceChargePercent * 100)),
+	}
+}
+
+// LineTax is the tax breakdown of one sale line. Base is the taxable amount
+// (DPP) and Total is what the customer pays for the line.
+type LineTax struct {
+	ServiceCharge int
+	RateBps       int
+	Base          int
+	Amount        int
+	Total         int
+}
+
+// Line computes the service charge and tax for a line whose price after
+// discounts is net. The service charge is taken on net and is taxed along
+// with it. For inclusive pricing the tax is extracted from net plus service
+// charge instead of being added on top. Exempt lines still pay the service
+// charge but no tax.
+func (c TaxConfig) Line(net int, exempt bool) LineTax {
+	lt := LineTax{
+		ServiceCharge: roundDiv(net*c.ServiceChargeBps, 10000),
+	}
+	if !exempt {
+		lt.RateBps = c.RateBps
+	}
+
+	gross := net + lt.ServiceCharge
+	if c.Inclusive {
+		lt.Amount = roundDiv(gross*lt.RateBps, 10000+lt.RateBps)
+		lt.Base = gross - lt.Amount
+		lt.Total = gross
+	} else {
+		lt.Amount = roundDiv(gross*lt.RateBps, 10000)
+		lt.Base = gross
+		lt.Total = gross + lt.Amount
+	}
+	return lt
+}
+
+// roundDiv divides rounding half up; both operands are non-negative.
+func roundDiv(a, b int) int {
+	return (a + b/2) / b
+}
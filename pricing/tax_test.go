@@ -0,0 +1,50 @@
+package pricing
+
+import "testing"
+
+func TestNewTaxConfig(t *testing.T) {
+	got := NewTaxConfig(11, true, 5.5)
+	want := TaxConfig{RateBps: 1100, Inclusive: true, ServiceChargeBps: 550}
+	if got != want {
+		t.Errorf("NewTaxConfig(11, true, 5.5) = %+v, want %+v", got, want)
+	}
+	if got := NewTaxConfig(0.1, false, 0); got.RateBps != 10 {
+		t.Errorf("NewTaxConfig(0.1).RateBps = %d, want 10", got.RateBps)
+	}
+}
+
+func TestTaxConfigLine(t *testing.T) {
+	ppn := TaxConfig{RateBps: 1100}
+	ppnInclusive := TaxConfig{RateBps: 1100, Inclusive: true}
+	withService := TaxConfig{RateBps: 1100, ServiceChargeBps: 500}
+	withServiceInclusive := TaxConfig{RateBps: 1100, Inclusive: true, ServiceChargeBps: 500}
+
+	tests := []struct {
+		name   string
+		config TaxConfig
+		net    int
+		exempt bool
+		want   LineTax
+	}{
+		{name: "no tax", config: TaxConfig{}, net: 10000, want: LineTax{Base: 10000, Total: 10000}},
+		{name: "exclusive", config: ppn, net: 10000, want: LineTax{RateBps: 1100, Base: 10000, Amount: 1100, Total: 11100}},
+		{name: "inclusive", config: ppnInclusive, net: 11100, want: LineTax{RateBps: 1100, Base: 10000, Amount: 1100, Total: 11100}},
+		{name: "exclusive rounds half up", config: ppn, net: 50, want: LineTax{RateBps: 1100, Base: 50, Amount: 6, Total: 56}},
+		{name: "exclusive rounds down", config: ppn, net: 40, want: LineTax{RateBps: 1100, Base: 40, Amount: 4, Total: 44}},
+		{name: "inclusive rounds", config: ppnInclusive, net: 1000, want: LineTax{RateBps: 1100, Base: 901, Amount: 99, Total: 1000}},
+		{name: "exempt exclusive", config: ppn, net: 10000, exempt: true, want: LineTax{Base: 10000, Total: 10000}},
+		{name: "exempt inclusive", config: ppnInclusive, net: 10000, exempt: true, want: LineTax{Base: 10000, Total: 10000}},
+		{name: "service charge is taxed", config: withService, net: 10000, want: LineTax{ServiceCharge: 500, RateBps: 1100, Base: 10500, Amount: 1155, Total: 11655}},
+		{name: "service charge inclusive", config: withServiceInclusive, net: 10000, want: LineTax{ServiceCharge: 500, RateBps: 1100, Base: 9459, Amount: 1041, Total: 10500}},
+		{name: "exempt still pays service charge", config: withService, net: 10000, exempt: true, want: LineTax{ServiceCharge: 500, Base: 10500, Total: 10500}},
+		{name: "service charge rounds half up", config: withService, net: 10, want: LineTax{ServiceCharge: 1, RateBps: 1100, Base: 11, Amount: 1, Total: 12}},
+		{name: "zero net", config: withService, net: 0, want: LineTax{RateBps: 1100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.Line(tt.net, tt.exempt); got != tt.want {
+				t.Errorf("Line(%d, %v) = %+v, want %+v", tt.net, tt.exempt, got, tt.want)
+			}
+		})
+	}
+}
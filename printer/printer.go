@@ -0,0 +1,31 @@
+// Package printer talks to thermal printers that accept raw jobs over TCP,
+// usually on port 9100. Anything listening on the address will do, so a
+// local stand-in such as `nc -l 9100 > out.bin` can replace the printer.
+package printer
+
+import (
+	"net"
+	"time"
+)
+
+const DefaultPort = "9100"
+
+// Send writes data to the printer at address as one raw job. An address
+// without a port uses DefaultPort.
+func Send(address string, data []byte, timeout time.Duration) error {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, DefaultPort)
+	}
+
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+	_, err = conn.Write(data)
+	return err
+}
@@ -0,0 +1,39 @@
+package receipt
+
+import "bytes"
+
+// ESC/POS commands understood by common 58mm and 80mm thermal printers.
+var (
+	escInit        = []byte{0x1b, '@'}
+	escAlignLeft   = []byte{0x1b, 'a', 0}
+	escAlignCenter = []byte{0x1b, 'a', 1}
+	escBoldOn      = []byte{0x1b, 'E', 1}
+	escBoldOff     = []byte{0x1b, 'E', 0}
+	escFeed        = []byte{0x1b, 'd', 4}
+	gsPartialCut   = []byte{0x1d, 'V', 66, 0}
+)
+
+// ESCPOS renders the receipt as an ESC/POS job that ends with a paper cut.
+func (r *Receipt) ESCPOS() []byte {
+	var b bytes.Buffer
+	b.Write(escInit)
+	for _, l := range r.lines {
+		if l.align == alignCenter {
+			b.Write(escAlignCenter)
+		} else {
+			b.Write(escAlignLeft)
+		}
+		if l.bold {
+			b.Write(escBoldOn)
+		}
+		b.WriteString(l.text)
+		b.WriteByte('\n')
+		if l.bold {
+			b.Write(escBoldOff)
+		}
+	}
+	b.Write(escAlignLeft)
+	b.Write(escFeed)
+	b.Write(gsPartialCut)
+	return b.Bytes()
+}
@@ -0,0 +1,83 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	pointsPerMM    = 72 / 25.4
+	pdfMarginMM    = 3
+	pdfLineSpacing = 1.2
+)
+
+// PDF renders the receipt as a single page PDF as wide as the paper and as
+// tall as the receipt, using the built-in Courier font so no font needs to
+// be embedded.
+func (r *Receipt) PDF() []byte {
+	pageWidth := float64(r.paper) * pointsPerMM
+	margin := pdfMarginMM * pointsPerMM
+	// Courier glyphs are 0.6 em wide; size the font so a full line fits.
+	fontSize := (pageWidth - 2*margin) / (float64(r.columns) * 0.6)
+	leading := fontSize * pdfLineSpacing
+	pageHeight := 2*margin + leading*float64(len(r.lines))
+
+	var content bytes.Buffer
+	fmt.Fprintf(&content, "BT\n%.2f TL\n%.2f %.2f Td\n", leading, margin, pageHeight-margin-fontSize)
+	currentFont := ""
+	for _, l := range r.lines {
+		font := "/F1"
+		if l.bold {
+			font = "/F2"
+		}
+		if font != currentFont {
+			fmt.Fprintf(&content, "%s %.2f Tf\n", font, fontSize)
+			currentFont = font
+		}
+		fmt.Fprintf(&content, "(%s) Tj T*\n", pdfEscape(r.padded(l)))
+	}
+	content.WriteString("ET\n")
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Contents 4 0 R /Resources << /Font << /F1 5 0 R /F2 6 0 R >> >> >>", pageWidth, pageHeight),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>",
+	}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.Bytes()
+}
+
+// pdfEscape escapes a string for a PDF literal. Characters outside ASCII are
+// replaced since the standard fonts only cover WinAnsi.
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(c)
+		case c < 0x20 || c > 0x7e:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,210 @@
+package receipt
+
+import (
+	"fmt"
+	"kasir-api/models"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	Paper58mm = 58
+	Paper80mm = 80
+)
+
+// Store is the header and footer printed on every receipt.
+type Store struct {
+	Name    string
+	Address string
+	Phone   string
+	Footer  string
+}
+
+type align int
+
+const (
+	alignLeft align = iota
+	alignCenter
+)
+
+type line struct {
+	text  string
+	align align
+	bold  bool
+}
+
+// Receipt is a laid out receipt for one paper width. It renders to plain
+// text, ESC/POS or PDF from the same lines so every format prints the same.
+type Receipt struct {
+	paper   int
+	columns int
+	lines   []line
+}
+
+// Columns returns the characters per line for a paper width in millimetres,
+// matching the default font of common thermal printers.
+func Columns(paper int) int {
+	if paper == Paper80mm {
+		return 48
+	}
+	return 32
+}
+
+func IsValidPaper(paper int) bool {
+	return paper == Paper58mm || paper == Paper80mm
+}
+
+var paymentMethodLabels = map[string]string{
+	models.PaymentMethodCash:      "Tunai",
+	models.PaymentMethodDebitCard: "Kartu Debit",
+	models.PaymentMethodQRIS:      "QRIS",
+	models.PaymentMethodEWallet:   "E-Wallet",
+	models.PaymentMethodTransfer:  "Transfer",
+}
+
+// Build lays out the receipt for t. The transaction must be loaded with its
+// details and payments.
+func Build(store Store, t *models.Transaction, paper int) *Receipt {
+	r := &Receipt{paper: paper, columns: Columns(paper)}
+
+	if store.Name != "" {
+		r.add(line{text: store.Name, align: alignCenter, bold: true})
+	}
+	for _, text := range []string{store.Address, store.Phone} {
+		if text != "" {
+			r.add(line{text: text, align: alignCenter})
+		}
+	}
+	r.separator()
+
+	r.pair("No. "+strconv.Itoa(t.ID), t.CreatedAt.Format("02/01/2006 15:04"))
+	switch t.Status {
+	case models.TransactionStatusVoided:
+		r.add(line{text: "*** DIBATALKAN ***", align: alignCenter, bold: true})
+	case models.TransactionStatusRefunded:
+		r.add(line{text: "*** DIREFUND ***", align: alignCenter, bold: true})
+	}
+	r.separator()
+
+	for _, d := range t.Details {
+		r.add(line{text: d.ProductName})
+		r.pair(fmt.Sprintf("  %d x %s", d.Quantity, Rupiah(d.UnitPrice)), Rupiah(d.UnitPrice*d.Quantity))
+		if d.Discount > 0 {
+			r.pair("  Diskon", "-"+Rupiah(d.Discount))
+		}
+	}
+	r.separator()
+
+	r.pair("Subtotal", Rupiah(t.GrossAmount))
+	for _, p := range t.Promotions {
+		r.pair(p.Name, "-"+Rupiah(p.Amount))
+	}
+	if t.ServiceCharge > 0 {
+		r.pair("Service Charge", Rupiah(t.ServiceCharge))
+	}
+	if t.TaxAmount > 0 {
+		label := "PPN"
+		if t.TaxInclusive {
+			label = "PPN (termasuk)"
+		}
+		r.pair(label, Rupiah(t.TaxAmount))
+	}
+	r.add(line{text: r.justify("TOTAL", Rupiah(t.TotalAmount)), bold: true})
+
+	if len(t.Payments) > 0 {
+		r.separator()
+		for _, p := range t.Payments {
+			label, ok := paymentMethodLabels[p.Method]
+			if !ok {
+				label = p.Method
+			}
+			r.pair(label, Rupiah(p.Tendered))
+		}
+		if t.ChangeAmount > 0 {
+			r.pair("Kembali", Rupiah(t.ChangeAmount))
+		}
+	}
+
+	if store.Footer != "" {
+		r.separator()
+		for _, text := range strings.Split(store.Footer, "\n") {
+			r.add(line{text: text, align: alignCenter})
+		}
+	}
+
+	return r
+}
+
+// add appends l, wrapping text that is wider than the paper.
+func (r *Receipt) add(l line) {
+	runes := []rune(l.text)
+	for len(runes) > r.columns {
+		r.lines = append(r.lines, line{text: string(runes[:r.columns]), align: l.align, bold: l.bold})
+		runes = runes[r.columns:]
+	}
+	l.text = string(runes)
+	r.lines = append(r.lines, l)
+}
+
+func (r *Receipt) separator() {
+	r.lines = append(r.lines, line{text: strings.Repeat("-", r.columns)})
+}
+
+// pair prints label on the left and value on the right of one line. A label
+// too long to share the line gets a line of its own.
+func (r *Receipt) pair(label, value string) {
+	if utf8.RuneCountInString(label)+utf8.RuneCountInString(value)+1 > r.columns {
+		r.add(line{text: label})
+		label = ""
+	}
+	r.add(line{text: r.justify(label, value)})
+}
+
+func (r *Receipt) justify(left, right string) string {
+	gap := r.columns - utf8.RuneCountInString(left) - utf8.RuneCountInString(right)
+	if gap < 1 {
+		gap = 1
+	}
+	return left + strings.Repeat(" ", gap) + right
+}
+
+// padded returns the line text as it appears on a fixed width page.
+func (r *Receipt) padded(l line) string {
+	if l.align != alignCenter {
+		return l.text
+	}
+	pad := (r.columns - utf8.RuneCountInString(l.text)) / 2
+	if pad < 0 {
+		pad = 0
+	}
+	return strings.Repeat(" ", pad) + l.text
+}
+
+// Text renders the receipt as plain text, one receipt line per text line.
+func (r *Receipt) Text() string {
+	var b strings.Builder
+	for _, l := range r.lines {
+		b.WriteString(r.padded(l))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Rupiah formats an amount with dots as thousands separators.
+func Rupiah(amount int) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	digits := strconv.Itoa(amount)
+	var b strings.Builder
+	for i, c := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(c)
+	}
+	return sign + b.String()
+}
@@ -0,0 +1,69 @@
+package receipt
+
+import (
+	"kasir-api/models"
+	"strconv"
+)
+
+// BuildShiftSummary lays out the X report of an open shift or the Z report
+// of a closed one.
+func BuildShiftSummary(store Store, s *models.ShiftSummary, paper int) *Receipt {
+	r := &Receipt{paper: paper, columns: Columns(paper)}
+
+	if store.Name != "" {
+		r.add(line{text: store.Name, align: alignCenter, bold: true})
+	}
+	title := "LAPORAN X"
+	if s.Shift.Status == models.ShiftStatusClosed {
+		title = "LAPORAN Z"
+	}
+	r.add(line{text: title, align: alignCenter, bold: true})
+	r.separator()
+
+	r.pair("Shift", strconv.Itoa(s.Shift.ID))
+	r.pair("Kasir", s.Shift.CashierName)
+	r.pair("Buka", s.Shift.OpenedAt.Format("02/01/2006 15:04"))
+	if s.Shift.ClosedAt != nil {
+		r.pair("Tutup", s.Shift.ClosedAt.Format("02/01/2006 15:04"))
+	}
+	r.separator()
+
+	r.pair("Transaksi", strconv.Itoa(s.TotalTransaksi))
+	r.pair("Dibatalkan", strconv.Itoa(s.TotalBatal))
+	r.pair("Penjualan Kotor", Rupiah(s.GrossSales))
+	if s.TotalDiskon > 0 {
+		r.pair("Diskon", "-"+Rupiah(s.TotalDiskon))
+	}
+	if s.TotalService > 0 {
+		r.pair("Service Charge", Rupiah(s.TotalService))
+	}
+	if s.TotalPajak > 0 {
+		r.pair("PPN", Rupiah(s.TotalPajak))
+	}
+	r.add(line{text: r.justify("TOTAL", Rupiah(s.TotalSales)), bold: true})
+
+	if len(s.MetodePembayaran) > 0 {
+		r.separator()
+		for _, m := range s.MetodePembayaran {
+			label, ok := paymentMethodLabels[m.Method]
+			if !ok {
+				label = m.Method
+			}
+			r.pair(label+" ("+strconv.Itoa(m.TotalTransaksi)+")", Rupiah(m.TotalAmount))
+		}
+	}
+	r.separator()
+
+	r.pair("Modal Awal", Rupiah(s.Shift.OpeningCash))
+	r.pair("Penjualan Tunai", Rupiah(s.CashSales))
+	r.pair("Kas Masuk", Rupiah(s.PayIn))
+	r.pair("Kas Keluar", "-"+Rupiah(s.PayOut))
+	r.pair("Retur Tunai", "-"+Rupiah(s.CashRefunds))
+	r.add(line{text: r.justify("Kas Seharusnya", Rupiah(s.ExpectedCash)), bold: true})
+	if s.CountedCash != nil && s.Difference != nil {
+		r.pair("Kas Dihitung", Rupiah(*s.CountedCash))
+		r.pair("Selisih", Rupiah(*s.Difference))
+	}
+
+	return r
+}
@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"strings"
+)
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Create appends an entry to the audit log. Empty Before or After are
+// stored as NULL.
+func (repo *AuditRepository) Create(ctx context.Context, e *models.AuditEntry) error {
+	var before, after interface{}
+	if len(e.Before) > 0 {
+		before = []byte(e.Before)
+	}
+	if len(e.After) > 0 {
+		after = []byte(e.After)
+	}
+	return repo.db.QueryRowContext(ctx, `
+		INSERT INTO audit_logs (actor_type, actor_id, actor_name, action, entity_type, entity_id, before, after, request_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at`,
+		e.ActorType, e.ActorID, e.ActorName, e.Action, e.EntityType, e.EntityID, before, after, e.RequestID,
+	).Scan(&e.ID, &e.CreatedAt)
+}
+
+func (repo *AuditRepository) GetAll(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, int, error) {
+	conditions := make([]string, 0)
+	args := make([]interface{}, 0)
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.ActorType != "" {
+		addCondition("actor_type = $%d", filter.ActorType)
+	}
+	if filter.ActorID != 0 {
+		addCondition("actor_id = $%d", filter.ActorID)
+	}
+	if filter.Action != "" {
+		addCondition("action = $%d", filter.Action)
+	}
+	if filter.EntityType != "" {
+		addCondition("entity_type = $%d", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		addCondition("entity_id = $%d", filter.EntityID)
+	}
+	if filter.RequestID != "" {
+		addCondition("request_id = $%d", filter.RequestID)
+	}
+	if filter.StartDate != "" {
+		addCondition("created_at >= $%d", filter.StartDate)
+	}
+	if filter.EndDate != "" {
+		addCondition("created_at <= $%d", filter.EndDate)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := repo.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_logs"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, actor_type, actor_id, actor_name, action, entity_type, entity_id, before, after, request_id, created_at
+		FROM audit_logs` + where + fmt.Sprintf(`
+		ORDER BY id DESC
+		LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+
+	rows, err := repo.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := make([]models.AuditEntry, 0)
+	for rows.Next() {
+		var e models.AuditEntry
+		var before, after []byte
+		err := rows.Scan(&e.ID, &e.ActorType, &e.ActorID, &e.ActorName, &e.Action, &e.EntityType, &e.EntityID, &before, &after, &e.RequestID, &e.CreatedAt)
+		if err != nil {
+			return nil, 0, err
+		}
+		e.Before = before
+		e.After = after
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
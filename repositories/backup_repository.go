@@ -0,0 +1,184 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"kasir-api/models"
+)
+
+type BackupRepository struct {
+	db *sql.DB
+}
+
+func NewBackupRepository(db *sql.DB) *BackupRepository {
+	return &BackupRepository{db: db}
+}
+
+// Export reads the whole catalog in one snapshot, ordered by ID.
+func (repo *BackupRepository) Export(ctx context.Context) (*models.CatalogBackup, error) {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SET TRANSACTION ISOLATION LEVEL REPEATABLE READ READ ONLY"); err != nil {
+		return nil, err
+	}
+
+	backup := &models.CatalogBackup{
+		Version:    models.CatalogBackupVersion,
+		Categories: make([]models.Category, 0),
+		Products:   make([]models.Product, 0),
+		Promotions: make([]models.Promotion, 0),
+	}
+	if err := tx.QueryRowContext(ctx, "SELECT NOW()").Scan(&backup.ExportedAt); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT id, name, description, tax_exempt, created_at FROM categories ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var c models.Category
+		if err := rows.Scan(&c.ID, &c.Name, &c.Description, &c.TaxExempt, &c.CreatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		backup.Categories = append(backup.Categories, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = tx.QueryContext(ctx, `
+		SELECT p.id, p.category_id, COALESCE(c.name, ''), COALESCE(p.sku, ''), COALESCE(p.plu, 0), p.name, p.price, p.cost, p.stock, p.tax_exempt, p.created_at,
+			`+productBarcodes+`
+		FROM products p
+		LEFT JOIN categories c ON c.id = p.category_id
+		ORDER BY p.id`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var p models.Product
+		var barcodes []byte
+		if err := rows.Scan(&p.ID, &p.CategoryID, &p.CategoryName, &p.SKU, &p.PLU, &p.Name, &p.Price, &p.Cost, &p.Stock, &p.TaxExempt, &p.CreatedAt, &barcodes); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if err := json.Unmarshal(barcodes, &p.Barcodes); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		backup.Products = append(backup.Products, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = tx.QueryContext(ctx, "SELECT"+promotionColumns+" FROM promotions ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var p models.Promotion
+		if err := scanPromotion(rows, &p); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		backup.Promotions = append(backup.Promotions, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return backup, tx.Commit()
+}
+
+// Import writes the catalog in one transaction. Rows are matched by ID:
+// existing ones are overwritten and missing ones are inserted with their
+// original ID. Rows not in the backup are left alone. Existing products keep
+// their stock unless overwriteStock is set.
+func (repo *BackupRepository) Import(ctx context.Context, backup *models.CatalogBackup, overwriteStock bool) (*models.ImportResult, error) {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result := &models.ImportResult{}
+	for _, c := range backup.Categories {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO categories (id, name, description, tax_exempt, created_at)
+			VALUES ($1, $2, $3, $4, COALESCE($5, NOW()))
+			ON CONFLICT (id) DO UPDATE
+			SET name = EXCLUDED.name, description = EXCLUDED.description, tax_exempt = EXCLUDED.tax_exempt`,
+			c.ID, c.Name, c.Description, c.TaxExempt, c.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		result.Categories++
+	}
+
+	for _, p := range backup.Products {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO products (id, category_id, sku, plu, name, price, cost, stock, tax_exempt, created_at)
+			VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, 0), $5, $6, $7, $8, $9, COALESCE($10, NOW()))
+			ON CONFLICT (id) DO UPDATE
+			SET category_id = EXCLUDED.category_id, sku = EXCLUDED.sku, plu = EXCLUDED.plu, name = EXCLUDED.name,
+				price = EXCLUDED.price, cost = EXCLUDED.cost, tax_exempt = EXCLUDED.tax_exempt,
+				stock = CASE WHEN $11 THEN EXCLUDED.stock ELSE products.stock END`,
+			p.ID, p.CategoryID, p.SKU, p.PLU, p.Name, p.Price, p.Cost, p.Stock, p.TaxExempt, p.CreatedAt, overwriteStock)
+		if err != nil {
+			return nil, productWriteError(err)
+		}
+		// Backups written before barcodes existed have none; keep the
+		// current ones then.
+		if p.Barcodes != nil {
+			if err := replaceBarcodes(ctx, tx, p.ID, p.Barcodes); err != nil {
+				return nil, err
+			}
+		}
+		result.Products++
+	}
+
+	for _, p := range backup.Promotions {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO promotions
+				(id, name, type, scope, product_id, category_id, value, buy_qty, get_qty,
+				min_spend, max_discount, starts_at, ends_at, priority, stackable, active, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, COALESCE($17, NOW()))
+			ON CONFLICT (id) DO UPDATE
+			SET name = EXCLUDED.name, type = EXCLUDED.type, scope = EXCLUDED.scope,
+				product_id = EXCLUDED.product_id, category_id = EXCLUDED.category_id, value = EXCLUDED.value,
+				buy_qty = EXCLUDED.buy_qty, get_qty = EXCLUDED.get_qty, min_spend = EXCLUDED.min_spend,
+				max_discount = EXCLUDED.max_discount, starts_at = EXCLUDED.starts_at, ends_at = EXCLUDED.ends_at,
+				priority = EXCLUDED.priority, stackable = EXCLUDED.stackable, active = EXCLUDED.active`,
+			p.ID, p.Name, p.Type, p.Scope, p.ProductID, p.CategoryID, p.Value, p.BuyQty, p.GetQty,
+			p.MinSpend, p.MaxDiscount, p.StartsAt, p.EndsAt, p.Priority, p.Stackable, p.Active, p.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		result.Promotions++
+	}
+
+	// Inserting explicit IDs does not move the sequences, so without this the
+	// next row created through the API would collide with an imported one.
+	for _, table := range []string{"categories", "products", "promotions"} {
+		_, err := tx.ExecContext(ctx, `SELECT setval(pg_get_serial_sequence('`+table+`', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM `+table)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"kasir-api/models"
 )
@@ -13,9 +14,9 @@ func NewCategoryRepository(db *sql.DB) *CategoryRepository {
 	return &CategoryRepository{db: db}
 }
 
-func (repo *CategoryRepository) GetAll() ([]models.Category, error) {
-	query := "SELECT id, name, description, created_at FROM categories"
-	rows, err := repo.db.Query(query)
+func (repo *CategoryRepository) GetAll(ctx context.Context) ([]models.Category, error) {
+	query := "SELECT id, name, description, tax_exempt, created_at FROM categories"
+	rows, err := repo.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -24,7 +25,7 @@ func (repo *CategoryRepository) GetAll() ([]models.Category, error) {
 	categories := make([]models.Category, 0)
 	for rows.Next() {
 		var c models.Category
-		err := rows.Scan(&c.ID, &c.Name, &c.Description, &c.CreatedAt)
+		err := rows.Scan(&c.ID, &c.Name, &c.Description, &c.TaxExempt, &c.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -34,32 +35,35 @@ func (repo *CategoryRepository) GetAll() ([]models.Category, error) {
 	return categories, nil
 }
 
-func (repo *CategoryRepository) Create(category *models.Category) error {
-	query := "INSERT INTO categories (name, description) VALUES ($1, $2) RETURNING id, created_at"
-	err := repo.db.QueryRow(query, category.Name, category.Description).Scan(&category.ID, &category.CreatedAt)
+func (repo *CategoryRepository) Create(ctx context.Context, category *models.Category) error {
+	query := "INSERT INTO categories (name, description, tax_exempt) VALUES ($1, $2, $3) RETURNING id, created_at"
+	err := repo.db.QueryRowContext(ctx, query, category.Name, category.Description, category.TaxExempt).Scan(&category.ID, &category.CreatedAt)
 	return err
 }
 
-func (repo *CategoryRepository) GetByID(id string) (*models.Category, error) {
-	query := "SELECT id, name, description, created_at FROM categories WHERE id = $1"
-	row := repo.db.QueryRow(query, id)
+func (repo *CategoryRepository) GetByID(ctx context.Context, id string) (*models.Category, error) {
+	query := "SELECT id, name, description, tax_exempt, created_at FROM categories WHERE id = $1"
+	row := repo.db.QueryRowContext(ctx, query, id)
 	var c models.Category
-	err := row.Scan(&c.ID, &c.Name, &c.Description, &c.CreatedAt)
+	err := row.Scan(&c.ID, &c.Name, &c.Description, &c.TaxExempt, &c.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
 	return &c, nil
 }
 
-func (repo *CategoryRepository) Update(id string, category *models.Category) error {
-	query := "UPDATE categories SET name = $1, description = $2 WHERE id = $3"
-	_, err := repo.db.Exec(query, category.Name, category.Description, id)
+func (repo *CategoryRepository) Update(ctx context.Context, id string, category *models.Category) error {
+	query := "UPDATE categories SET name = $1, description = $2, tax_exempt = $3 WHERE id = $4"
+	_, err := repo.db.ExecContext(ctx, query, category.Name, category.Description, category.TaxExempt, id)
 	return err
 }
 
-func (repo *CategoryRepository) Delete(id string) error {
+func (repo *CategoryRepository) Delete(ctx context.Context, id string) error {
 	query := "DELETE FROM categories WHERE id = $1"
-	res, err := repo.db.Exec(query, id)
+	res, err := repo.db.ExecContext(ctx, query, id)
+	if isForeignKeyViolation(err) {
+		return models.ErrCategoryInUse
+	}
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,27 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// foreignKeyViolation is the PostgreSQL error code for a row that points at
+// a missing row, or a delete of a row that is still pointed at.
+const foreignKeyViolation = "23503"
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation
+}
+
+// uniqueViolation is the PostgreSQL error code for a row that duplicates a
+// unique key.
+const uniqueViolation = "23505"
+
+// isUniqueViolation reports whether err is a duplicate key on the named
+// constraint or index.
+func isUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == constraint
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"kasir-api/models"
+	"time"
+)
+
+type PrintJobRepository struct {
+	db *sql.DB
+}
+
+func NewPrintJobRepository(db *sql.DB) *PrintJobRepository {
+	return &PrintJobRepository{db: db}
+}
+
+const printJobColumns = `
+	id, transaction_id, printer_address, paper_width, status, attempts, last_error,
+	next_attempt_at, printed_at, created_at, updated_at`
+
+func scanPrintJob(row interface{ Scan(...interface{}) error }, j *models.PrintJob) error {
+	return row.Scan(
+		&j.ID,
+		&j.TransactionID,
+		&j.PrinterAddress,
+		&j.PaperWidth,
+		&j.Status,
+		&j.Attempts,
+		&j.LastError,
+		&j.NextAttemptAt,
+		&j.PrintedAt,
+		&j.CreatedAt,
+		&j.UpdatedAt,
+	)
+}
+
+func (repo *PrintJobRepository) Create(ctx context.Context, job *models.PrintJob) error {
+	row := repo.db.QueryRowContext(ctx,
+		"INSERT INTO print_jobs (transaction_id, printer_address, paper_width) VALUES ($1, $2, $3) RETURNING"+printJobColumns,
+		job.TransactionID, job.PrinterAddress, job.PaperWidth,
+	)
+	return scanPrintJob(row, job)
+}
+
+func (repo *PrintJobRepository) GetByID(ctx context.Context, id int) (*models.PrintJob, error) {
+	var j models.PrintJob
+	row := repo.db.QueryRowContext(ctx, "SELECT"+printJobColumns+" FROM print_jobs WHERE id = $1", id)
+	if err := scanPrintJob(row, &j); err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+func (repo *PrintJobRepository) GetByTransactionID(ctx context.Context, transactionID int) ([]models.PrintJob, error) {
+	rows, err := repo.db.QueryContext(ctx, "SELECT"+printJobColumns+" FROM print_jobs WHERE transaction_id = $1 ORDER BY id", transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := make([]models.PrintJob, 0)
+	for rows.Next() {
+		var j models.PrintJob
+		if err := scanPrintJob(rows, &j); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+// ClaimDue moves the oldest queued job whose retry time has come to printing,
+// leased to the caller for lease, and returns it, or sql.ErrNoRows when
+// nothing is due. SKIP LOCKED lets several workers poll the same table
+// without printing a job twice.
+func (repo *PrintJobRepository) ClaimDue(ctx context.Context, lease time.Duration) (*models.PrintJob, error) {
+	var j models.PrintJob
+	row := repo.db.QueryRowContext(ctx, `
+		UPDATE print_jobs
+		SET status = 'printing', attempts = attempts + 1, lease_expires_at = NOW() + $1 * INTERVAL '1 second', updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM print_jobs
+			WHERE status = 'queued' AND next_attempt_at <= NOW()
+			ORDER BY id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING`+printJobColumns, lease.Seconds())
+	if err := scanPrintJob(row, &j); err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+func (repo *PrintJobRepository) MarkDone(ctx context.Context, id int) error {
+	_, err := repo.db.ExecContext(ctx,
+		"UPDATE print_jobs SET status = 'done', last_error = '', lease_expires_at = NULL, printed_at = NOW(), updated_at = NOW() WHERE id = $1",
+		id,
+	)
+	return err
+}
+
+// MarkRetry queues the job again to be tried after delay, timed by the
+// database clock like ClaimDue.
+func (repo *PrintJobRepository) MarkRetry(ctx context.Context, id int, lastError string, delay time.Duration) error {
+	_, err := repo.db.ExecContext(ctx,
+		"UPDATE print_jobs SET status = 'queued', last_error = $1, next_attempt_at = NOW() + $2 * INTERVAL '1 second', lease_expires_at = NULL, updated_at = NOW() WHERE id = $3",
+		lastError, delay.Seconds(), id,
+	)
+	return err
+}
+
+func (repo *PrintJobRepository) MarkFailed(ctx context.Context, id int, lastError string) error {
+	_, err := repo.db.ExecContext(ctx,
+		"UPDATE print_jobs SET status = 'failed', last_error = $1, lease_expires_at = NULL, updated_at = NOW() WHERE id = $2",
+		lastError, id,
+	)
+	return err
+}
+
+// RequeueExpired puts back in the queue the jobs whose worker let the lease
+// run out, such as one that stopped mid-send. Jobs still leased to a live
+// worker, on this server or another, are left alone.
+func (repo *PrintJobRepository) RequeueExpired(ctx context.Context) error {
+	_, err := repo.db.ExecContext(ctx, `
+		UPDATE print_jobs
+		SET status = 'queued', lease_expires_at = NULL, updated_at = NOW()
+		WHERE status = 'printing' AND lease_expires_at < NOW()`)
+	return err
+}
@@ -1,8 +1,12 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"kasir-api/models"
+	"strings"
 )
 
 type ProductRepository struct {
@@ -13,96 +17,331 @@ func NewProductRepository(db *sql.DB) *ProductRepository {
 	return &ProductRepository{db: db}
 }
 
-func (repo *ProductRepository) GetAll(nameFilter string) ([]models.Product, error) {
+// productBarcodes selects the barcodes of product p as a JSON array.
+const productBarcodes = `COALESCE((SELECT json_agg(b.barcode ORDER BY b.barcode) FROM product_barcodes b WHERE b.product_id = p.id), '[]')`
+
+// productSortColumns maps the sorts accepted by GetAll to their column.
+var productSortColumns = map[string]string{
+	models.ProductSortName:      "p.name",
+	models.ProductSortPrice:     "p.price",
+	models.ProductSortStock:     "p.stock",
+	models.ProductSortCreatedAt: "p.created_at",
+}
+
+// escapeLike escapes the LIKE wildcards in s, so that it matches literally
+// in a pattern with ESCAPE '\'.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// keysetCondition selects the rows after the cursor whose sort value and ID
+// are the parameters numbered from param: later in ascending order, earlier
+// in descending order. The ID breaks ties like it does in ORDER BY.
+func keysetCondition(column, order string, param int) string {
+	compare := ">"
+	if order == models.SortDesc {
+		compare = "<"
+	}
+	return fmt.Sprintf("(%s, p.id) %s ($%d, $%d)", column, compare, param, param+1)
+}
+
+// GetAll returns a page of the products matching filter, sorted by
+// filter.Sort with the ID breaking ties, and the number of matching
+// products across all pages.
+func (repo *ProductRepository) GetAll(ctx context.Context, filter models.ProductFilter) ([]models.Product, int, error) {
+	conditions := make([]string, 0)
+	args := make([]interface{}, 0)
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Name != "" {
+		addCondition(`p.name ILIKE $%d ESCAPE '\'`, "%"+escapeLike(filter.Name)+"%")
+	}
+	if filter.CategoryID != 0 {
+		addCondition("p.category_id = $%d", filter.CategoryID)
+	}
+	if filter.MinPrice != nil {
+		addCondition("p.price >= $%d", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		addCondition("p.price <= $%d", *filter.MaxPrice)
+	}
+	if filter.InStock != nil {
+		if *filter.InStock {
+			conditions = append(conditions, "p.stock > 0")
+		} else {
+			conditions = append(conditions, "p.stock <= 0")
+		}
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := repo.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM products p"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	column, ok := productSortColumns[filter.Sort]
+	if !ok {
+		column = productSortColumns[models.ProductSortName]
+	}
+	direction := "ASC"
+	if filter.Order == models.SortDesc {
+		direction = "DESC"
+	}
+
+	// The cursor narrows the page but not the total.
+	if filter.After != nil {
+		args = append(args, filter.After.Value, filter.After.ID)
+		conditions = append(conditions, keysetCondition(column, filter.Order, len(args)-1))
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
 	query := `
 		SELECT 
 			p.id,
 			p.category_id,
+			COALESCE(p.sku, ''),
+			COALESCE(p.plu, 0),
 			p.name, 
 			p.price, 
+			p.cost,
 			p.stock, 
+			p.tax_exempt,
 			p.created_at,
-			c.name AS category_name
+			c.name AS category_name,
+			` + productBarcodes + `
 		FROM products p
-		LEFT JOIN categories c ON p.category_id = c.id
-	`
-
-	var args []interface{}
-	if nameFilter != "" {
-		query += " WHERE p.name ILIKE $1"
-		args = append(args, "%"+nameFilter+"%")
-	}
+		LEFT JOIN categories c ON p.category_id = c.id` + where + fmt.Sprintf(`
+		ORDER BY %s %s, p.id %s
+		LIMIT $%d OFFSET $%d`, column, direction, direction, len(args)+1, len(args)+2)
+	args = append(args, filter.Limit, filter.Offset)
 
-	rows, err := repo.db.Query(query, args...)
+	rows, err := repo.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
 	products := make([]models.Product, 0)
 	for rows.Next() {
 		var p models.Product
+		var barcodes []byte
 		err := rows.Scan(
 			&p.ID,
 			&p.CategoryID,
+			&p.SKU,
+			&p.PLU,
 			&p.Name,
 			&p.Price,
+			&p.Cost,
 			&p.Stock,
+			&p.TaxExempt,
 			&p.CreatedAt,
 			&p.CategoryName,
+			&barcodes,
 		)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
+		}
+		if err := json.Unmarshal(barcodes, &p.Barcodes); err != nil {
+			return nil, 0, err
 		}
 		products = append(products, p)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
 
-	return products, nil
+	return products, total, nil
 }
 
-func (repo *ProductRepository) Create(product *models.Product) error {
-	query := "INSERT INTO products (category_id, name, price, stock) VALUES ($1, $2, $3, $4) RETURNING id, created_at"
-	err := repo.db.QueryRow(query, product.CategoryID, product.Name, product.Price, product.Stock).Scan(&product.ID, &product.CreatedAt)
-	return err
+// Create inserts the product and its barcodes in one transaction.
+func (repo *ProductRepository) Create(ctx context.Context, product *models.Product) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "INSERT INTO products (category_id, sku, plu, name, price, cost, stock, tax_exempt) VALUES ($1, NULLIF($2, ''), NULLIF($3, 0), $4, $5, $6, $7, $8) RETURNING id, created_at"
+	err = tx.QueryRowContext(ctx, query, product.CategoryID, product.SKU, product.PLU, product.Name, product.Price, product.Cost, product.Stock, product.TaxExempt).Scan(&product.ID, &product.CreatedAt)
+	if err != nil {
+		return productWriteError(err)
+	}
+	if err := replaceBarcodes(ctx, tx, product.ID, product.Barcodes); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (repo *ProductRepository) GetByID(id string) (*models.Product, error) {
+func (repo *ProductRepository) GetByID(ctx context.Context, id string) (*models.Product, error) {
 	query := `
 		SELECT 
 			p.id, 
 			p.category_id, 
+			COALESCE(p.sku, ''),
+			COALESCE(p.plu, 0),
 			p.name, 
 			p.price, 
+			p.cost,
 			p.stock, 
-			p.created_at
+			p.tax_exempt,
+			p.created_at,
+			` + productBarcodes + `
 		FROM products p
 		WHERE p.id = $1
 	`
-	row := repo.db.QueryRow(query, id)
+	row := repo.db.QueryRowContext(ctx, query, id)
 	var p models.Product
+	var barcodes []byte
 	err := row.Scan(
 		&p.ID,
 		&p.CategoryID,
+		&p.SKU,
+		&p.PLU,
+		&p.Name,
+		&p.Price,
+		&p.Cost,
+		&p.Stock,
+		&p.TaxExempt,
+		&p.CreatedAt,
+		&barcodes,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(barcodes, &p.Barcodes); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetByBarcode finds the product carrying barcode, with its category name.
+// It is on the scanning path at the counter, so it stays a single lookup on
+// the barcode primary key.
+func (repo *ProductRepository) GetByBarcode(ctx context.Context, barcode string) (*models.Product, error) {
+	return repo.lookup(ctx, "JOIN product_barcodes pb ON pb.product_id = p.id WHERE pb.barcode = $1", barcode)
+}
+
+// GetByPLU finds the product with the PLU printed on scale labels, with its
+// category name.
+func (repo *ProductRepository) GetByPLU(ctx context.Context, plu int) (*models.Product, error) {
+	return repo.lookup(ctx, "WHERE p.plu = $1", plu)
+}
+
+// lookup reads the one product matched by clause, which may join further
+// tables and filters on $1.
+func (repo *ProductRepository) lookup(ctx context.Context, clause string, arg interface{}) (*models.Product, error) {
+	query := `
+		SELECT
+			p.id,
+			p.category_id,
+			COALESCE(c.name, ''),
+			COALESCE(p.sku, ''),
+			COALESCE(p.plu, 0),
+			p.name,
+			p.price,
+			p.cost,
+			p.stock,
+			p.tax_exempt,
+			p.created_at,
+			` + productBarcodes + `
+		FROM products p
+		LEFT JOIN categories c ON c.id = p.category_id
+		` + clause
+	var p models.Product
+	var barcodes []byte
+	err := repo.db.QueryRowContext(ctx, query, arg).Scan(
+		&p.ID,
+		&p.CategoryID,
+		&p.CategoryName,
+		&p.SKU,
+		&p.PLU,
 		&p.Name,
 		&p.Price,
+		&p.Cost,
 		&p.Stock,
+		&p.TaxExempt,
 		&p.CreatedAt,
+		&barcodes,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if err := json.Unmarshal(barcodes, &p.Barcodes); err != nil {
+		return nil, err
+	}
 	return &p, nil
 }
 
-func (repo *ProductRepository) Update(id string, product *models.Product) error {
-	query := "UPDATE products SET category_id = $1, name = $2, price = $3, stock = $4 WHERE id = $5"
-	_, err := repo.db.Exec(query, product.CategoryID, product.Name, product.Price, product.Stock, id)
+// Update overwrites the product and replaces its barcodes in one
+// transaction. It returns sql.ErrNoRows when the product does not exist.
+func (repo *ProductRepository) Update(ctx context.Context, id string, product *models.Product) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var productID int
+	query := "UPDATE products SET category_id = $1, sku = NULLIF($2, ''), plu = NULLIF($3, 0), name = $4, price = $5, cost = $6, stock = $7, tax_exempt = $8 WHERE id = $9 RETURNING id"
+	err = tx.QueryRowContext(ctx, query, product.CategoryID, product.SKU, product.PLU, product.Name, product.Price, product.Cost, product.Stock, product.TaxExempt, id).Scan(&productID)
+	if err != nil {
+		return productWriteError(err)
+	}
+	if err := replaceBarcodes(ctx, tx, productID, product.Barcodes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// replaceBarcodes sets the barcodes of a product to exactly barcodes.
+func replaceBarcodes(ctx context.Context, tx *sql.Tx, productID int, barcodes []string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM product_barcodes WHERE product_id = $1", productID); err != nil {
+		return err
+	}
+	for _, barcode := range barcodes {
+		res, err := tx.ExecContext(ctx,
+			"INSERT INTO product_barcodes (barcode, product_id) VALUES ($1, $2) ON CONFLICT (barcode) DO NOTHING",
+			barcode, productID,
+		)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return models.NewBarcodeTakenError(barcode)
+		}
+	}
+	return nil
+}
+
+// productWriteError maps the constraint errors of a product insert or update
+// to domain errors.
+func productWriteError(err error) error {
+	switch {
+	case isForeignKeyViolation(err):
+		return models.ErrProductCategoryNotFound
+	case isUniqueViolation(err, "products_sku_key"):
+		return models.ErrSKUTaken
+	case isUniqueViolation(err, "products_plu_key"):
+		return models.ErrPLUTaken
+	}
 	return err
 }
 
-func (repo *ProductRepository) Delete(id string) error {
+func (repo *ProductRepository) Delete(ctx context.Context, id string) error {
 	query := "DELETE FROM products WHERE id = $1"
-	res, err := repo.db.Exec(query, id)
+	res, err := repo.db.ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
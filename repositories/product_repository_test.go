@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"kasir-api/models"
+	"testing"
+)
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "kopi", want: "kopi"},
+		{in: "100%", want: `100\%`},
+		{in: "a_b", want: `a\_b`},
+		{in: `c:\temp`, want: `c:\\temp`},
+		{in: `%_\`, want: `\%\_\\`},
+	}
+	for _, tt := range tests {
+		if got := escapeLike(tt.in); got != tt.want {
+			t.Errorf("escapeLike(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestKeysetCondition(t *testing.T) {
+	tests := []struct {
+		sort  string
+		order string
+		param int
+		want  string
+	}{
+		{sort: models.ProductSortName, order: models.SortAsc, param: 1, want: "(p.name, p.id) > ($1, $2)"},
+		{sort: models.ProductSortName, order: models.SortDesc, param: 1, want: "(p.name, p.id) < ($1, $2)"},
+		{sort: models.ProductSortPrice, order: models.SortAsc, param: 3, want: "(p.price, p.id) > ($3, $4)"},
+		{sort: models.ProductSortPrice, order: models.SortDesc, param: 3, want: "(p.price, p.id) < ($3, $4)"},
+		{sort: models.ProductSortStock, order: models.SortAsc, param: 2, want: "(p.stock, p.id) > ($2, $3)"},
+		{sort: models.ProductSortStock, order: models.SortDesc, param: 2, want: "(p.stock, p.id) < ($2, $3)"},
+		{sort: models.ProductSortCreatedAt, order: models.SortAsc, param: 5, want: "(p.created_at, p.id) > ($5, $6)"},
+		{sort: models.ProductSortCreatedAt, order: models.SortDesc, param: 5, want: "(p.created_at, p.id) < ($5, $6)"},
+		{sort: models.ProductSortName, order: "", param: 1, want: "(p.name, p.id) > ($1, $2)"},
+	}
+	for _, tt := range tests {
+		if got := keysetCondition(productSortColumns[tt.sort], tt.order, tt.param); got != tt.want {
+			t.Errorf("keysetCondition(%s, %q, %d) = %q, want %q", tt.sort, tt.order, tt.param, got, tt.want)
+		}
+	}
+}
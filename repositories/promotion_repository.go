@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"kasir-api/models"
+)
+
+type PromotionRepository struct {
+	db *sql.DB
+}
+
+func NewPromotionRepository(db *sql.DB) *PromotionRepository {
+	return &PromotionRepository{db: db}
+}
+
+const promotionColumns = `
+	id, name, type, scope, product_id, category_id, value, buy_qty, get_qty,
+	min_spend, max_discount, starts_at, ends_at, priority, stackable, active, created_at`
+
+func scanPromotion(row interface{ Scan(...interface{}) error }, p *models.Promotion) error {
+	return row.Scan(
+		&p.ID,
+		&p.Name,
+		&p.Type,
+		&p.Scope,
+		&p.ProductID,
+		&p.CategoryID,
+		&p.Value,
+		&p.BuyQty,
+		&p.GetQty,
+		&p.MinSpend,
+		&p.MaxDiscount,
+		&p.StartsAt,
+		&p.EndsAt,
+		&p.Priority,
+		&p.Stackable,
+		&p.Active,
+		&p.CreatedAt,
+	)
+}
+
+func (repo *PromotionRepository) query(ctx context.Context, query string, args ...interface{}) ([]models.Promotion, error) {
+	rows, err := repo.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	promotions := make([]models.Promotion, 0)
+	for rows.Next() {
+		var p models.Promotion
+		if err := scanPromotion(rows, &p); err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, p)
+	}
+
+	return promotions, rows.Err()
+}
+
+func (repo *PromotionRepository) GetAll(ctx context.Context) ([]models.Promotion, error) {
+	return repo.query(ctx, "SELECT"+promotionColumns+" FROM promotions ORDER BY priority DESC, id")
+}
+
+// GetActive returns the promotions that are switched on and whose validity
+// window includes the current time.
+func (repo *PromotionRepository) GetActive(ctx context.Context) ([]models.Promotion, error) {
+	return repo.query(ctx, "SELECT"+promotionColumns+`
+		FROM promotions
+		WHERE active
+			AND (starts_at IS NULL OR starts_at <= NOW())
+			AND (ends_at IS NULL OR ends_at >= NOW())
+		ORDER BY priority DESC, id`)
+}
+
+func (repo *PromotionRepository) Create(ctx context.Context, p *models.Promotion) error {
+	query := `
+		INSERT INTO promotions
+			(name, type, scope, product_id, category_id, value, buy_qty, get_qty,
+			min_spend, max_discount, starts_at, ends_at, priority, stackable, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING id, created_at`
+	err := repo.db.QueryRowContext(ctx,
+		query,
+		p.Name, p.Type, p.Scope, p.ProductID, p.CategoryID, p.Value, p.BuyQty, p.GetQty,
+		p.MinSpend, p.MaxDiscount, p.StartsAt, p.EndsAt, p.Priority, p.Stackable, p.Active,
+	).Scan(&p.ID, &p.CreatedAt)
+	if isForeignKeyViolation(err) {
+		return models.ErrInvalidPromotionTarget
+	}
+	return err
+}
+
+func (repo *PromotionRepository) GetByID(ctx context.Context, id string) (*models.Promotion, error) {
+	row := repo.db.QueryRowContext(ctx, "SELECT"+promotionColumns+" FROM promotions WHERE id = $1", id)
+	var p models.Promotion
+	if err := scanPromotion(row, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (repo *PromotionRepository) Update(ctx context.Context, id string, p *models.Promotion) error {
+	query := `
+		UPDATE promotions SET
+			name = $1, type = $2, scope = $3, product_id = $4, category_id = $5, value = $6,
+			buy_qty = $7, get_qty = $8, min_spend = $9, max_discount = $10, starts_at = $11,
+			ends_at = $12, priority = $13, stackable = $14, active = $15
+		WHERE id = $16`
+	res, err := repo.db.ExecContext(ctx,
+		query,
+		p.Name, p.Type, p.Scope, p.ProductID, p.CategoryID, p.Value, p.BuyQty, p.GetQty,
+		p.MinSpend, p.MaxDiscount, p.StartsAt, p.EndsAt, p.Priority, p.Stackable, p.Active, id,
+	)
+	if isForeignKeyViolation(err) {
+		return models.ErrInvalidPromotionTarget
+	}
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (repo *PromotionRepository) Delete(ctx context.Context, id string) error {
+	query := "DELETE FROM promotions WHERE id = $1"
+	res, err := repo.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
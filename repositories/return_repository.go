@@ -0,0 +1,280 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"kasir-api/models"
+	"sort"
+)
+
+type ReturnRepository struct {
+	db *sql.DB
+	// requireShift refuses cash refunds while no shift is open.
+	requireShift bool
+}
+
+func NewReturnRepository(db *sql.DB, requireShift bool) *ReturnRepository {
+	return &ReturnRepository{db: db, requireShift: requireShift}
+}
+
+type returnableLine struct {
+	productID      int
+	productName    string
+	quantity       int
+	total          int
+	taxAmount      int
+	returnedQty    int
+	returnedAmount int
+	returnedTax    int
+}
+
+// loadReturnableLines reads the lines of a transaction with what has been
+// returned of each so far, keyed by transaction detail ID, along with the IDs
+// in line order.
+func loadReturnableLines(ctx context.Context, tx *sql.Tx, transactionID int) (map[int]returnableLine, []int, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			td.id,
+			COALESCE(td.product_id, 0),
+			td.product_name,
+			td.quantity,
+			td.total,
+			td.tax_amount,
+			COALESCE(SUM(rd.quantity), 0),
+			COALESCE(SUM(rd.amount), 0),
+			COALESCE(SUM(rd.tax_amount), 0)
+		FROM transaction_details td
+		LEFT JOIN return_details rd ON rd.transaction_detail_id = td.id
+		WHERE td.transaction_id = $1
+		GROUP BY td.id
+		ORDER BY td.id
+	`, transactionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	lines := make(map[int]returnableLine)
+	ids := make([]int, 0)
+	for rows.Next() {
+		var id int
+		var l returnableLine
+		err := rows.Scan(&id, &l.productID, &l.productName, &l.quantity, &l.total, &l.taxAmount, &l.returnedQty, &l.returnedAmount, &l.returnedTax)
+		if err != nil {
+			return nil, nil, err
+		}
+		lines[id] = l
+		ids = append(ids, id)
+	}
+	return lines, ids, rows.Err()
+}
+
+// insertReturn records ret with its details, setting their IDs. The refund
+// method defaults to cash, which is paid out of the drawer of an open shift
+// picked by shiftID as in checkout.
+func insertReturn(ctx context.Context, tx *sql.Tx, ret *models.Return, details []models.ReturnDetail, shiftID int, requireShift bool) error {
+	if ret.RefundMethod == "" {
+		ret.RefundMethod = models.PaymentMethodCash
+	}
+	// Cash is paid out of a drawer, so it is counted against that shift's
+	// expected cash.
+	if ret.RefundMethod == models.PaymentMethodCash {
+		id, err := lockOpenShift(ctx, tx, shiftID, requireShift)
+		if err != nil {
+			return err
+		}
+		ret.ShiftID = id
+	}
+	err := tx.QueryRowContext(ctx,
+		"INSERT INTO returns (transaction_id, total_amount, full_refund, refund_method, shift_id, reason, performed_by) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at",
+		ret.TransactionID, ret.TotalAmount, ret.FullRefund, ret.RefundMethod, ret.ShiftID, ret.Reason, ret.PerformedBy,
+	).Scan(&ret.ID, &ret.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	insertDetailQuery := "INSERT INTO return_details (return_id, transaction_detail_id, product_id, quantity, amount, tax_amount) VALUES ($1, $2, NULLIF($3, 0), $4, $5, $6) RETURNING id"
+	for i := range details {
+		detail := &details[i]
+		detail.ReturnID = ret.ID
+		err := tx.QueryRowContext(ctx,
+			insertDetailQuery,
+			detail.ReturnID,
+			detail.TransactionDetailID,
+			detail.ProductID,
+			detail.Quantity,
+			detail.Amount,
+			detail.TaxAmount,
+		).Scan(&detail.ID)
+		if err != nil {
+			return err
+		}
+	}
+	ret.Details = details
+	return nil
+}
+
+func (repo *ReturnRepository) Create(ctx context.Context, transactionID int, req models.ReturnRequest) (*models.Return, error) {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Locking the transaction serializes returns against each other and
+	// against a void or refund of the same sale.
+	var status string
+	err = tx.QueryRowContext(ctx, "SELECT status FROM transactions WHERE id = $1 FOR UPDATE", transactionID).Scan(&status)
+	if err != nil {
+		return nil, err
+	}
+	if status != models.TransactionStatusCompleted {
+		return nil, models.ErrTransactionNotCompleted
+	}
+
+	lines, _, err := loadReturnableLines(ctx, tx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	requested := make(map[int]int)
+	detailIDs := make([]int, 0)
+	for _, item := range req.Items {
+		if _, ok := lines[item.TransactionDetailID]; !ok {
+			return nil, models.ErrReturnDetailNotFound.WithDetails(map[string]int{"transaction_detail_id": item.TransactionDetailID})
+		}
+		if _, seen := requested[item.TransactionDetailID]; !seen {
+			detailIDs = append(detailIDs, item.TransactionDetailID)
+		}
+		requested[item.TransactionDetailID] += item.Quantity
+	}
+
+	excess := make([]models.ReturnExcess, 0)
+	for _, id := range detailIDs {
+		l := lines[id]
+		if returnable := l.quantity - l.returnedQty; requested[id] > returnable {
+			excess = append(excess, models.ReturnExcess{
+				TransactionDetailID: id,
+				ProductName:         l.productName,
+				Requested:           requested[id],
+				Returnable:          returnable,
+			})
+		}
+	}
+	if len(excess) > 0 {
+		return nil, models.NewReturnQuantityError(excess)
+	}
+
+	restock := make(map[int]int)
+	details := make([]models.ReturnDetail, 0, len(detailIDs))
+	totalAmount := 0
+	for _, id := range detailIDs {
+		l := lines[id]
+		// What the customer paid for the line, tax included, is prorated by
+		// quantity; returning everything that is left refunds the exact
+		// remainder so rounding never drifts.
+		amount := l.total * requested[id] / l.quantity
+		tax := l.taxAmount * requested[id] / l.quantity
+		if l.returnedQty+requested[id] == l.quantity {
+			amount = l.total - l.returnedAmount
+			tax = l.taxAmount - l.returnedTax
+		}
+		totalAmount += amount
+		// Products deleted since the sale have nothing left to restock.
+		if l.productID != 0 {
+			restock[l.productID] += requested[id]
+		}
+
+		details = append(details, models.ReturnDetail{
+			TransactionDetailID: id,
+			ProductID:           l.productID,
+			ProductName:         l.productName,
+			Quantity:            requested[id],
+			Amount:              amount,
+			TaxAmount:           tax,
+		})
+	}
+
+	productIDs := make([]int, 0, len(restock))
+	for id := range restock {
+		productIDs = append(productIDs, id)
+	}
+	sort.Ints(productIDs)
+	for _, id := range productIDs {
+		_, err := tx.ExecContext(ctx, "UPDATE products SET stock = stock + $1 WHERE id = $2", restock[id], id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ret := &models.Return{
+		TransactionID: transactionID,
+		TotalAmount:   totalAmount,
+		RefundMethod:  req.RefundMethod,
+		Reason:        req.Reason,
+		PerformedBy:   req.PerformedBy,
+	}
+	if err := insertReturn(ctx, tx, ret, details, req.ShiftID, repo.requireShift); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (repo *ReturnRepository) GetByTransactionID(ctx context.Context, transactionID int) ([]models.Return, error) {
+	rows, err := repo.db.QueryContext(ctx, `
+		SELECT id, transaction_id, total_amount, full_refund, refund_method, shift_id, reason, performed_by, created_at
+		FROM returns
+		WHERE transaction_id = $1
+		ORDER BY id
+	`, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	returns := make([]models.Return, 0)
+	index := make(map[int]int)
+	for rows.Next() {
+		var r models.Return
+		err := rows.Scan(&r.ID, &r.TransactionID, &r.TotalAmount, &r.FullRefund, &r.RefundMethod, &r.ShiftID, &r.Reason, &r.PerformedBy, &r.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		r.Details = make([]models.ReturnDetail, 0)
+		index[r.ID] = len(returns)
+		returns = append(returns, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	detailRows, err := repo.db.QueryContext(ctx, `
+		SELECT rd.id, rd.return_id, rd.transaction_detail_id, COALESCE(rd.product_id, 0), td.product_name, rd.quantity, rd.amount, rd.tax_amount
+		FROM return_details rd
+		JOIN returns r ON r.id = rd.return_id
+		JOIN transaction_details td ON td.id = rd.transaction_detail_id
+		WHERE r.transaction_id = $1
+		ORDER BY rd.id
+	`, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer detailRows.Close()
+
+	for detailRows.Next() {
+		var d models.ReturnDetail
+		err := detailRows.Scan(&d.ID, &d.ReturnID, &d.TransactionDetailID, &d.ProductID, &d.ProductName, &d.Quantity, &d.Amount, &d.TaxAmount)
+		if err != nil {
+			return nil, err
+		}
+		r := &returns[index[d.ReturnID]]
+		r.Details = append(r.Details, d)
+	}
+
+	return returns, detailRows.Err()
+}
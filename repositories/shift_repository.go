@@ -0,0 +1,319 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"kasir-api/models"
+)
+
+type ShiftRepository struct {
+	db *sql.DB
+}
+
+func NewShiftRepository(db *sql.DB) *ShiftRepository {
+	return &ShiftRepository{db: db}
+}
+
+// queryer is the part of *sql.DB and *sql.Tx the shift summary needs, so the
+// same queries serve the X report and the close of a shift.
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+const shiftColumns = `
+	id, cashier_name, status, opening_cash, expected_cash, counted_cash, close_note, opened_at, closed_at`
+
+func scanShift(row interface{ Scan(...interface{}) error }, s *models.Shift) error {
+	return row.Scan(
+		&s.ID,
+		&s.CashierName,
+		&s.Status,
+		&s.OpeningCash,
+		&s.ExpectedCash,
+		&s.CountedCash,
+		&s.CloseNote,
+		&s.OpenedAt,
+		&s.ClosedAt,
+	)
+}
+
+// Open starts a shift for the cashier. The partial unique index on open
+// shifts makes a second open shift for the same cashier fail.
+func (repo *ShiftRepository) Open(ctx context.Context, req models.OpenShiftRequest) (*models.Shift, error) {
+	var s models.Shift
+	row := repo.db.QueryRowContext(ctx, `
+		INSERT INTO shifts (cashier_name, opening_cash) VALUES ($1, $2)
+		ON CONFLICT (cashier_name) WHERE status = 'open' DO NOTHING
+		RETURNING`+shiftColumns,
+		req.CashierName, req.OpeningCash,
+	)
+	err := scanShift(row, &s)
+	if err == sql.ErrNoRows {
+		return nil, models.ErrShiftAlreadyOpen
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (repo *ShiftRepository) GetByID(ctx context.Context, id int) (*models.Shift, error) {
+	var s models.Shift
+	row := repo.db.QueryRowContext(ctx, "SELECT"+shiftColumns+" FROM shifts WHERE id = $1", id)
+	if err := scanShift(row, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetAll lists shifts, newest first. An empty status lists every shift.
+func (repo *ShiftRepository) GetAll(ctx context.Context, status string) ([]models.Shift, error) {
+	query := "SELECT" + shiftColumns + " FROM shifts"
+	args := []interface{}{}
+	if status != "" {
+		query += " WHERE status = $1"
+		args = append(args, status)
+	}
+	query += " ORDER BY opened_at DESC, id DESC"
+
+	rows, err := repo.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shifts := make([]models.Shift, 0)
+	for rows.Next() {
+		var s models.Shift
+		if err := scanShift(rows, &s); err != nil {
+			return nil, err
+		}
+		shifts = append(shifts, s)
+	}
+	return shifts, rows.Err()
+}
+
+// AddCashMovement records a pay-in or pay-out on an open shift.
+func (repo *ShiftRepository) AddCashMovement(ctx context.Context, m *models.CashMovement) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockShiftForUpdate(ctx, tx, m.ShiftID); err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx,
+		"INSERT INTO shift_cash_movements (shift_id, type, amount, reason) VALUES ($1, $2, $3, $4) RETURNING id, created_at",
+		m.ShiftID, m.Type, m.Amount, m.Reason,
+	).Scan(&m.ID, &m.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Close records the counted cash and closes the shift, keeping the summary
+// as it stands as the shift's Z report. The row lock waits for checkouts
+// still holding the shift, so the expected cash includes every sale rung up
+// on it.
+func (repo *ShiftRepository) Close(ctx context.Context, id int, req models.CloseShiftRequest) (*models.ShiftSummary, error) {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockShiftForUpdate(ctx, tx, id); err != nil {
+		return nil, err
+	}
+
+	summary, err := buildShiftSummary(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	difference := *req.CountedCash - summary.ExpectedCash
+	summary.CountedCash = req.CountedCash
+	summary.Difference = &difference
+	closing, err := json.Marshal(summary)
+	if err != nil {
+		return nil, err
+	}
+
+	row := tx.QueryRowContext(ctx, `
+		UPDATE shifts
+		SET status = 'closed', expected_cash = $1, counted_cash = $2, close_note = $3, closing_summary = $4, closed_at = NOW()
+		WHERE id = $5
+		RETURNING`+shiftColumns,
+		summary.ExpectedCash, *req.CountedCash, req.Note, closing, id,
+	)
+	if err := scanShift(row, &summary.Shift); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// GetSummary returns the X report of an open shift or the Z report of a
+// closed one. The Z report is the summary kept when the shift was closed, so
+// returns and refunds made later do not change it.
+func (repo *ShiftRepository) GetSummary(ctx context.Context, id int) (*models.ShiftSummary, error) {
+	var closing []byte
+	err := repo.db.QueryRowContext(ctx, "SELECT closing_summary FROM shifts WHERE id = $1", id).Scan(&closing)
+	if err != nil {
+		return nil, err
+	}
+	if closing != nil {
+		summary := &models.ShiftSummary{}
+		if err := json.Unmarshal(closing, summary); err != nil {
+			return nil, err
+		}
+		// The summary was taken just before the shift was marked closed.
+		row := repo.db.QueryRowContext(ctx, "SELECT"+shiftColumns+" FROM shifts WHERE id = $1", id)
+		if err := scanShift(row, &summary.Shift); err != nil {
+			return nil, err
+		}
+		return summary, nil
+	}
+
+	summary, err := buildShiftSummary(ctx, repo.db, id)
+	if err != nil {
+		return nil, err
+	}
+	// Shifts closed before the summary was kept at least keep the expected
+	// cash worked out when they were closed.
+	if summary.Shift.ExpectedCash != nil {
+		summary.ExpectedCash = *summary.Shift.ExpectedCash
+	}
+	if summary.Shift.CountedCash != nil {
+		difference := *summary.Shift.CountedCash - summary.ExpectedCash
+		summary.CountedCash = summary.Shift.CountedCash
+		summary.Difference = &difference
+	}
+	return summary, nil
+}
+
+func lockShiftForUpdate(ctx context.Context, tx *sql.Tx, id int) error {
+	var status string
+	err := tx.QueryRowContext(ctx, "SELECT status FROM shifts WHERE id = $1 FOR UPDATE", id).Scan(&status)
+	if err != nil {
+		return err
+	}
+	if status != models.ShiftStatusOpen {
+		return models.ErrShiftNotOpen
+	}
+	return nil
+}
+
+func buildShiftSummary(ctx context.Context, q queryer, id int) (*models.ShiftSummary, error) {
+	summary := &models.ShiftSummary{}
+	row := q.QueryRowContext(ctx, "SELECT"+shiftColumns+" FROM shifts WHERE id = $1", id)
+	if err := scanShift(row, &summary.Shift); err != nil {
+		return nil, err
+	}
+
+	err := q.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE status <> 'voided'),
+			COUNT(*) FILTER (WHERE status = 'voided'),
+			COALESCE(SUM(gross_amount) FILTER (WHERE status <> 'voided'), 0),
+			COALESCE(SUM(discount_amount) FILTER (WHERE status <> 'voided'), 0),
+			COALESCE(SUM(tax_amount) FILTER (WHERE status <> 'voided'), 0),
+			COALESCE(SUM(service_charge) FILTER (WHERE status <> 'voided'), 0),
+			COALESCE(SUM(total_amount) FILTER (WHERE status <> 'voided'), 0)
+		FROM transactions
+		WHERE shift_id = $1
+	`, id).Scan(
+		&summary.TotalTransaksi,
+		&summary.TotalBatal,
+		&summary.GrossSales,
+		&summary.TotalDiskon,
+		&summary.TotalPajak,
+		&summary.TotalService,
+		&summary.TotalSales,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	methodRows, err := q.QueryContext(ctx, `
+		SELECT tp.method, COALESCE(SUM(tp.amount), 0), COUNT(DISTINCT tp.transaction_id)
+		FROM transaction_payments tp
+		JOIN transactions t ON t.id = tp.transaction_id
+		WHERE t.status <> 'voided' AND t.shift_id = $1
+		GROUP BY tp.method
+		ORDER BY tp.method
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer methodRows.Close()
+
+	summary.MetodePembayaran = make([]models.PaymentMethodSummary, 0)
+	for methodRows.Next() {
+		var m models.PaymentMethodSummary
+		if err := methodRows.Scan(&m.Method, &m.TotalAmount, &m.TotalTransaksi); err != nil {
+			return nil, err
+		}
+		if m.Method == models.PaymentMethodCash {
+			summary.CashSales = m.TotalAmount
+		}
+		summary.MetodePembayaran = append(summary.MetodePembayaran, m)
+	}
+	if err := methodRows.Err(); err != nil {
+		return nil, err
+	}
+
+	movementRows, err := q.QueryContext(ctx, `
+		SELECT id, shift_id, type, amount, reason, created_at
+		FROM shift_cash_movements
+		WHERE shift_id = $1
+		ORDER BY id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer movementRows.Close()
+
+	summary.CashMovements = make([]models.CashMovement, 0)
+	for movementRows.Next() {
+		var m models.CashMovement
+		if err := movementRows.Scan(&m.ID, &m.ShiftID, &m.Type, &m.Amount, &m.Reason, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		if m.Type == models.CashMovementPayIn {
+			summary.PayIn += m.Amount
+		} else {
+			summary.PayOut += m.Amount
+		}
+		summary.CashMovements = append(summary.CashMovements, m)
+	}
+	if err := movementRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Like the reports, only returns of sales that still count are taken out;
+	// a refund is a return of whatever was left of its sale, so together
+	// they never give back more than was sold.
+	err = q.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(r.total_amount), 0)
+		FROM returns r
+		JOIN transactions t ON t.id = r.transaction_id
+		WHERE r.shift_id = $1 AND r.refund_method = 'cash' AND t.status <> 'voided'
+	`, id).Scan(&summary.CashRefunds)
+	if err != nil {
+		return nil, err
+	}
+
+	summary.ExpectedCash = summary.Shift.OpeningCash + summary.CashSales + summary.PayIn - summary.PayOut - summary.CashRefunds
+	return summary, nil
+}
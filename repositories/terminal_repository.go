@@ -0,0 +1,167 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"kasir-api/models"
+)
+
+type TerminalRepository struct {
+	db *sql.DB
+}
+
+func NewTerminalRepository(db *sql.DB) *TerminalRepository {
+	return &TerminalRepository{db: db}
+}
+
+const terminalColumns = `
+	id, name, array_to_json(permissions), active, created_at, updated_at`
+
+func scanTerminal(row interface{ Scan(...interface{}) error }, t *models.Terminal) error {
+	var permissions []byte
+	err := row.Scan(
+		&t.ID,
+		&t.Name,
+		&permissions,
+		&t.Active,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(permissions, &t.Permissions)
+}
+
+const terminalAPIKeyColumns = `
+	id, terminal_id, prefix, created_at, last_used_at, revoked_at`
+
+func scanTerminalAPIKey(row interface{ Scan(...interface{}) error }, k *models.TerminalAPIKey) error {
+	return row.Scan(
+		&k.ID,
+		&k.TerminalID,
+		&k.Prefix,
+		&k.CreatedAt,
+		&k.LastUsedAt,
+		&k.RevokedAt,
+	)
+}
+
+func (repo *TerminalRepository) Create(ctx context.Context, t *models.Terminal) error {
+	row := repo.db.QueryRowContext(ctx,
+		"INSERT INTO terminals (name, permissions, active) VALUES ($1, $2, $3) RETURNING"+terminalColumns,
+		t.Name, t.Permissions, t.Active,
+	)
+	return scanTerminal(row, t)
+}
+
+func (repo *TerminalRepository) GetAll(ctx context.Context) ([]models.Terminal, error) {
+	rows, err := repo.db.QueryContext(ctx, "SELECT"+terminalColumns+" FROM terminals ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	terminals := make([]models.Terminal, 0)
+	for rows.Next() {
+		var t models.Terminal
+		if err := scanTerminal(rows, &t); err != nil {
+			return nil, err
+		}
+		terminals = append(terminals, t)
+	}
+	return terminals, rows.Err()
+}
+
+func (repo *TerminalRepository) GetByID(ctx context.Context, id int) (*models.Terminal, error) {
+	var t models.Terminal
+	row := repo.db.QueryRowContext(ctx, "SELECT"+terminalColumns+" FROM terminals WHERE id = $1", id)
+	if err := scanTerminal(row, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (repo *TerminalRepository) Update(ctx context.Context, t *models.Terminal) error {
+	row := repo.db.QueryRowContext(ctx,
+		"UPDATE terminals SET name = $1, permissions = $2, active = $3, updated_at = NOW() WHERE id = $4 RETURNING"+terminalColumns,
+		t.Name, t.Permissions, t.Active, t.ID,
+	)
+	return scanTerminal(row, t)
+}
+
+func (repo *TerminalRepository) CreateAPIKey(ctx context.Context, terminalID int, prefix, keyHash string) (*models.TerminalAPIKey, error) {
+	var k models.TerminalAPIKey
+	row := repo.db.QueryRowContext(ctx,
+		"INSERT INTO terminal_api_keys (terminal_id, prefix, key_hash) VALUES ($1, $2, $3) RETURNING"+terminalAPIKeyColumns,
+		terminalID, prefix, keyHash,
+	)
+	if err := scanTerminalAPIKey(row, &k); err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (repo *TerminalRepository) GetAPIKeys(ctx context.Context, terminalID int) ([]models.TerminalAPIKey, error) {
+	rows, err := repo.db.QueryContext(ctx, "SELECT"+terminalAPIKeyColumns+" FROM terminal_api_keys WHERE terminal_id = $1 ORDER BY id", terminalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]models.TerminalAPIKey, 0)
+	for rows.Next() {
+		var k models.TerminalAPIKey
+		if err := scanTerminalAPIKey(rows, &k); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey revokes a key of the terminal. Revoking an already revoked
+// key keeps its original revocation time.
+func (repo *TerminalRepository) RevokeAPIKey(ctx context.Context, terminalID, keyID int) (*models.TerminalAPIKey, error) {
+	var k models.TerminalAPIKey
+	row := repo.db.QueryRowContext(ctx, `
+		UPDATE terminal_api_keys SET revoked_at = COALESCE(revoked_at, NOW())
+		WHERE id = $1 AND terminal_id = $2
+		RETURNING`+terminalAPIKeyColumns,
+		keyID, terminalID,
+	)
+	if err := scanTerminalAPIKey(row, &k); err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// GetByAPIKey returns the active terminal owning an unrevoked key. The last
+// use is recorded at most once a minute to keep the lookup cheap.
+func (repo *TerminalRepository) GetByAPIKey(ctx context.Context, keyHash string) (*models.Terminal, error) {
+	var t models.Terminal
+	row := repo.db.QueryRowContext(ctx, `
+		SELECT t.id, t.name, array_to_json(t.permissions), t.active, t.created_at, t.updated_at
+		FROM terminal_api_keys k
+		JOIN terminals t ON t.id = k.terminal_id
+		WHERE k.key_hash = $1 AND k.revoked_at IS NULL AND t.active`,
+		keyHash,
+	)
+	err := scanTerminal(row, &t)
+	if err == sql.ErrNoRows {
+		return nil, models.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = repo.db.ExecContext(ctx,
+		"UPDATE terminal_api_keys SET last_used_at = NOW() WHERE key_hash = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')",
+		keyHash,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
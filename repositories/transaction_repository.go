@@ -1,166 +1,1012 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"kasir-api/barcode"
 	"kasir-api/models"
+	"kasir-api/pricing"
+	"sort"
+	"strings"
 	"time"
 )
 
 type TransactionRepository struct {
 	db *sql.DB
+	// requireShift refuses sales and cash refunds while no shift is open.
+	requireShift bool
 }
 
-func NewTransactionRepository(db *sql.DB) *TransactionRepository {
-	return &TransactionRepository{db: db}
+func NewTransactionRepository(db *sql.DB, requireShift bool) *TransactionRepository {
+	return &TransactionRepository{db: db, requireShift: requireShift}
 }
 
-func (repo *TransactionRepository) CreateTransaction(items []models.CheckoutItem) (*models.Transaction, error) {
-	tx, err:= repo.db.Begin()
+// CheckoutOptions is everything besides the request that decides how a
+// checkout is priced and stored.
+type CheckoutOptions struct {
+	Promotions []models.Promotion
+	Tax        pricing.TaxConfig
+	// ScaleFormats are the scale label layouts a barcode is tried against
+	// when no product carries it.
+	ScaleFormats []barcode.Format
+	// UseLock locks the product rows with SELECT ... FOR UPDATE.
+	UseLock bool
+	// IdempotencyKey, when set, is claimed for this checkout and stores its
+	// response for replays.
+	IdempotencyKey *models.IdempotencyKey
+	// KeyTTL is how long idempotency keys are kept; an older key with the
+	// same scope and value is taken over by this checkout.
+	KeyTTL time.Duration
+	// TerminalID is the terminal the sale was rung up on, 0 for none.
+	TerminalID int
+}
+
+// cartLine is a checkout item with its product ID resolved. label is set
+// when the item was a scale label; Quantity then already counts what the
+// label holds.
+type cartLine struct {
+	models.CheckoutItem
+	label *barcode.Label
+}
+
+// unitPrice is what one unit of the line costs given the product price.
+func (l cartLine) unitPrice(price int) int {
+	if l.label != nil {
+		return l.label.UnitPrice(price)
+	}
+	return price
+}
+
+// resolveBarcodes fills in the product ID for the items that name a
+// barcode. A barcode that no product carries is read as a scale label and
+// resolved by its PLU.
+func resolveBarcodes(ctx context.Context, tx *sql.Tx, items []models.CheckoutItem, formats []barcode.Format) ([]cartLine, error) {
+	lines := make([]cartLine, len(items))
+	for i, item := range items {
+		line := cartLine{CheckoutItem: item}
+		if item.Barcode != "" {
+			err := tx.QueryRowContext(ctx, "SELECT product_id FROM product_barcodes WHERE barcode = $1", item.Barcode).Scan(&line.ProductID)
+			if err == sql.ErrNoRows {
+				line.ProductID, line.label, err = resolveScaleLabel(ctx, tx, item.Barcode, formats)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if line.label != nil {
+			line.Quantity *= line.label.Quantity()
+		}
+		lines[i] = line
+	}
+	return lines, nil
+}
+
+// resolveScaleLabel parses code as a scale label and returns the ID of the
+// product with its PLU.
+func resolveScaleLabel(ctx context.Context, tx *sql.Tx, code string, formats []barcode.Format) (int, *barcode.Label, error) {
+	notFound := models.ErrProductNotFound.WithDetails(map[string]string{"barcode": code})
+	label, err := barcode.Parse(formats, code)
+	if err != nil {
+		return 0, nil, models.NewInvalidBarcodeError(code)
+	}
+	if label == nil {
+		return 0, nil, notFound
+	}
+	var productID int
+	err = tx.QueryRowContext(ctx, "SELECT id FROM products WHERE plu = $1", label.PLU).Scan(&productID)
+	if err == sql.ErrNoRows {
+		return 0, nil, notFound
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	return productID, label, nil
+}
+
+func (repo *TransactionRepository) CreateTransaction(ctx context.Context, req models.CheckoutRequest, opts CheckoutOptions) (*models.Transaction, error) {
+	key := opts.IdempotencyKey
+
+	tx, err:= repo.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback()
-	
-	totalAmount := 0
-	details := make([]models.TransactionDetail, 0)
 
+	// Claiming the key first makes a concurrent retry with the same key block
+	// on the unique index until this checkout commits or rolls back. A key
+	// past its retention window is reused as if it were new.
+	if key != nil {
+		var claimed string
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO idempotency_keys (scope, key, request_hash) VALUES ($1, $2, $3)
+			ON CONFLICT (scope, key) DO UPDATE
+			SET request_hash = EXCLUDED.request_hash, transaction_id = NULL, response = NULL, created_at = NOW()
+			WHERE idempotency_keys.created_at < NOW() - $4 * INTERVAL '1 second'
+			RETURNING key`,
+			key.Scope, key.Key, key.RequestHash, opts.KeyTTL.Seconds(),
+		).Scan(&claimed)
+		if err == sql.ErrNoRows {
+			return nil, models.ErrIdempotencyKeyInProgress
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	shiftID, err := lockOpenShift(ctx, tx, req.ShiftID, repo.requireShift)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := resolveBarcodes(ctx, tx, req.Items, opts.ScaleFormats)
+	if err != nil {
+		return nil, err
+	}
+
+	// The same product may appear on several cart lines, so stock is checked
+	// against the total quantity requested per product.
+	requested := make(map[int]int)
 	for _, item := range items {
-		var productPrice, stock int
-		var productName string
+		requested[item.ProductID] += item.Quantity
+	}
+	productIDs := make([]int, 0, len(requested))
+	for id := range requested {
+		productIDs = append(productIDs, id)
+	}
+	// Rows are always read (and locked) in ascending ID order so that two
+	// concurrent checkouts never wait on each other in opposite order.
+	sort.Ints(productIDs)
 
-		err := tx.QueryRow("SELECT name, price, stock FROM products WHERE id = $1", item.ProductID).Scan(&productName, &productPrice, &stock)
+	selectQuery := `
+		SELECT p.name, p.price, p.cost, p.stock, p.category_id, COALESCE(c.name, ''), p.tax_exempt OR COALESCE(c.tax_exempt, FALSE)
+		FROM products p
+		LEFT JOIN categories c ON c.id = p.category_id
+		WHERE p.id = $1`
+	if opts.UseLock {
+		selectQuery += " FOR UPDATE OF p"
+	}
+
+	products := make(map[int]models.Product, len(productIDs))
+	shortages := make([]models.StockShortage, 0)
+	for _, id := range productIDs {
+		var p models.Product
+		err := tx.QueryRowContext(ctx, selectQuery, id).Scan(&p.Name, &p.Price, &p.Cost, &p.Stock, &p.CategoryID, &p.CategoryName, &p.TaxExempt)
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("Product Id %d not found", item.ProductID)
+			return nil, models.ErrProductNotFound.WithDetails(map[string]int{"product_id": id})
 		}
 		if err != nil {
 			return nil, err
 		}
+		p.ID = id
+		products[id] = p
 
-		subTotal := productPrice * item.Quantity
-		totalAmount += subTotal
+		if p.Stock < requested[id] {
+			shortages = append(shortages, models.StockShortage{
+				ProductID: id,
+				ProductName: p.Name,
+				Requested: requested[id],
+				Available: p.Stock,
+			})
+		}
+	}
+	if len(shortages) > 0 {
+		return nil, models.NewInsufficientStockError(shortages)
+	}
 
-		_, err = tx.Exec("UPDATE products SET stock = stock - $1 WHERE id = $2", item.Quantity, item.ProductID)
+	// Without row locks another checkout may have sold the stock since it was
+	// read, so the decrement only applies while enough stock is left.
+	for _, id := range productIDs {
+		res, err := tx.ExecContext(ctx, "UPDATE products SET stock = stock - $1 WHERE id = $2 AND stock >= $1", requested[id], id)
 		if err != nil {
 			return nil, err
 		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		if affected == 0 {
+			var available int
+			if err := tx.QueryRowContext(ctx, "SELECT stock FROM products WHERE id = $1", id).Scan(&available); err != nil {
+				return nil, err
+			}
+			shortages = append(shortages, models.StockShortage{
+				ProductID: id,
+				ProductName: products[id].Name,
+				Requested: requested[id],
+				Available: available,
+			})
+		}
+	}
+	if len(shortages) > 0 {
+		return nil, models.NewInsufficientStockError(shortages)
+	}
+
+	lines := make([]pricing.Line, len(items))
+	for i, item := range items {
+		product := products[item.ProductID]
+		lines[i] = pricing.Line{
+			ProductID: item.ProductID,
+			CategoryID: product.CategoryID,
+			UnitPrice: item.unitPrice(product.Price),
+			Quantity: item.Quantity,
+		}
+	}
+	appliedPromotions := pricing.ApplyPromotions(lines, opts.Promotions)
+
+	grossAmount := 0
+	discountAmount := 0
+	serviceCharge := 0
+	taxAmount := 0
+	totalAmount := 0
+	details := make([]models.TransactionDetail, 0, len(items))
+	for i, item := range items {
+		product := products[item.ProductID]
+		line := lines[i]
+		subTotal := line.Gross() - line.Discount
+		tax := opts.Tax.Line(subTotal, product.TaxExempt)
+		grossAmount += line.Gross()
+		discountAmount += line.Discount
+		serviceCharge += tax.ServiceCharge
+		taxAmount += tax.Amount
+		totalAmount += tax.Total
 
+		categoryID := product.CategoryID
 		details = append(details, models.TransactionDetail{
 			ProductID: item.ProductID,
-			ProductName: productName,
+			ProductName: product.Name,
+			CategoryID: &categoryID,
+			CategoryName: product.CategoryName,
+			UnitPrice: line.UnitPrice,
+			UnitCost: product.Cost,
 			Quantity: item.Quantity,
+			Discount: line.Discount,
 			Subtotal: subTotal,
+			ServiceCharge: tax.ServiceCharge,
+			TaxRate: tax.RateBps,
+			TaxBase: tax.Base,
+			TaxAmount: tax.Amount,
+			Total: tax.Total,
 		})
 	}
 
+	payments, paidAmount, changeAmount, err := allocatePayments(totalAmount, req.Payments)
+	if err != nil {
+		return nil, err
+	}
+
+	var terminalID *int
+	if opts.TerminalID != 0 {
+		terminalID = &opts.TerminalID
+	}
+
 	var transactionID int
+	var status string
 	var createdAt time.Time
-	err = tx.QueryRow(
-		"INSERT INTO transactions (total_amount) VALUES ($1) RETURNING id, created_at",
-		totalAmount,
-	).Scan(&transactionID, &createdAt)
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO transactions
+			(gross_amount, discount_amount, service_charge, tax_amount, tax_inclusive, total_amount, paid_amount, change_amount, shift_id, terminal_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, status, created_at`,
+		grossAmount, discountAmount, serviceCharge, taxAmount, opts.Tax.Inclusive, totalAmount, paidAmount, changeAmount, shiftID, terminalID,
+	).Scan(&transactionID, &status, &createdAt)
 	if err != nil {
 		return nil, err
 	}
 
-	insertDetailQuery := "INSERT INTO transaction_details (transaction_id, product_id, quantity, subtotal) VALUES ($1, $2, $3, $4) RETURNING id"
+	insertDetailQuery := `
+		INSERT INTO transaction_details
+			(transaction_id, product_id, product_name, category_id, category_name, unit_price, unit_cost,
+			quantity, discount, subtotal, service_charge, tax_rate, tax_base, tax_amount, total)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING id`
 
 	for i := range details {
 		detail := &details[i]
 		detail.TransactionID = transactionID
 
-		err = tx.QueryRow(
+		err = tx.QueryRowContext(ctx,
 			insertDetailQuery,
 			detail.TransactionID,
 			detail.ProductID,
+			detail.ProductName,
+			detail.CategoryID,
+			detail.CategoryName,
+			detail.UnitPrice,
+			detail.UnitCost,
 			detail.Quantity,
+			detail.Discount,
 			detail.Subtotal,
+			detail.ServiceCharge,
+			detail.TaxRate,
+			detail.TaxBase,
+			detail.TaxAmount,
+			detail.Total,
 		).Scan(&detail.ID)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		return nil, err
+	insertPaymentQuery := "INSERT INTO transaction_payments (transaction_id, method, amount, tendered, reference) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at"
+
+	for i := range payments {
+		payment := &payments[i]
+		payment.TransactionID = transactionID
+
+		err = tx.QueryRowContext(ctx,
+			insertPaymentQuery,
+			payment.TransactionID,
+			payment.Method,
+			payment.Amount,
+			payment.Tendered,
+			payment.Reference,
+		).Scan(&payment.ID, &payment.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return &models.Transaction{
+	for _, promo := range appliedPromotions {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO transaction_promotions (transaction_id, promotion_id, promotion_name, amount) VALUES ($1, $2, $3, $4)",
+			transactionID, promo.PromotionID, promo.Name, promo.Amount,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	transaction := &models.Transaction{
 		ID: transactionID,
+		GrossAmount: grossAmount,
+		DiscountAmount: discountAmount,
+		ServiceCharge: serviceCharge,
+		TaxAmount: taxAmount,
+		TaxInclusive: opts.Tax.Inclusive,
 		TotalAmount: totalAmount,
+		PaidAmount: paidAmount,
+		ChangeAmount: changeAmount,
+		ShiftID: shiftID,
+		TerminalID: terminalID,
+		Status: status,
 		CreatedAt: createdAt,
 		Details: details,
-	}, nil
+		Payments: payments,
+		Promotions: appliedPromotions,
+	}
+
+	if key != nil {
+		response, err := json.Marshal(transaction)
+		if err != nil {
+			return nil, err
+		}
+		_, err = tx.ExecContext(ctx,
+			"UPDATE idempotency_keys SET transaction_id = $1, response = $2 WHERE scope = $3 AND key = $4",
+			transactionID, response, key.Scope, key.Key,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
 }
 
-func (repo *TransactionRepository) GetReport() (*models.TransactionReport, error) {
-	var totalRevenue int
-	var totalTransaksi int
+// lockOpenShift returns the shift a sale, return or refund is recorded on,
+// holding a share lock on it so the shift cannot be closed until the
+// transaction commits. With shiftID 0 the only open shift is used; see
+// pickOpenShift.
+func lockOpenShift(ctx context.Context, tx *sql.Tx, shiftID int, requireShift bool) (*int, error) {
+	if shiftID != 0 {
+		err := tx.QueryRowContext(ctx, "SELECT id FROM shifts WHERE id = $1 AND status = 'open' FOR SHARE", shiftID).Scan(&shiftID)
+		if err == sql.ErrNoRows {
+			return nil, models.ErrShiftNotOpen
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &shiftID, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT id FROM shifts WHERE status = 'open' ORDER BY id LIMIT 2 FOR SHARE")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	open := make([]int, 0, 2)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		open = append(open, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return pickOpenShift(open, requireShift)
+}
+
+// pickOpenShift picks the shift for a request that names none from the open
+// shifts, of which at most two are needed. With no shift open the request is
+// recorded outside any shift, as in stores that do not use shifts, unless
+// requireShift is set.
+func pickOpenShift(open []int, requireShift bool) (*int, error) {
+	switch len(open) {
+	case 0:
+		if requireShift {
+			return nil, models.ErrNoOpenShift
+		}
+		return nil, nil
+	case 1:
+		return &open[0], nil
+	default:
+		return nil, models.ErrShiftRequired
+	}
+}
+
+// allocatePayments applies the payments to total and returns the resulting
+// lines along with the total tendered and the change due. Change can only be
+// taken out of cash.
+func allocatePayments(total int, payments []models.CheckoutPayment) ([]models.TransactionPayment, int, int, error) {
+	paid := 0
+	nonCash := 0
+	for _, p := range payments {
+		paid += p.Amount
+		if p.Method != models.PaymentMethodCash {
+			nonCash += p.Amount
+		}
+	}
+	if paid < total {
+		return nil, 0, 0, models.NewInsufficientPaymentError(total, paid)
+	}
+	if nonCash > total {
+		return nil, 0, 0, models.ErrNonCashOverpayment
+	}
+
+	change := paid - total
+	remaining := change
+	lines := make([]models.TransactionPayment, len(payments))
+	for i := len(payments) - 1; i >= 0; i-- {
+		p := payments[i]
+		applied := p.Amount
+		if p.Method == models.PaymentMethodCash && remaining > 0 {
+			deduct := min(remaining, applied)
+			applied -= deduct
+			remaining -= deduct
+		}
+		lines[i] = models.TransactionPayment{
+			Method:    p.Method,
+			Amount:    applied,
+			Tendered:  p.Amount,
+			Reference: p.Reference,
+		}
+	}
+	return lines, paid, change, nil
+}
+
+// GetIdempotencyKey returns the key used by scope, or sql.ErrNoRows when
+// there is none or it is older than ttl.
+func (repo *TransactionRepository) GetIdempotencyKey(ctx context.Context, scope, key string, ttl time.Duration) (*models.IdempotencyKey, error) {
+	query := `
+		SELECT scope, key, request_hash, transaction_id, response, created_at
+		FROM idempotency_keys
+		WHERE scope = $1 AND key = $2 AND created_at >= NOW() - $3 * INTERVAL '1 second'`
+	var k models.IdempotencyKey
+	var response []byte
+	err := repo.db.QueryRowContext(ctx, query, scope, key, ttl.Seconds()).Scan(&k.Scope, &k.Key, &k.RequestHash, &k.TransactionID, &response, &k.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	k.Response = response
+	return &k, nil
+}
+
+// DeleteExpiredIdempotencyKeys removes the keys older than ttl.
+func (repo *TransactionRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, ttl time.Duration) error {
+	_, err := repo.db.ExecContext(ctx,
+		"DELETE FROM idempotency_keys WHERE created_at < NOW() - $1 * INTERVAL '1 second'",
+		ttl.Seconds(),
+	)
+	return err
+}
 
-	err := repo.db.QueryRow(`
-		SELECT COALESCE(SUM(total_amount), 0), COALESCE(COUNT(*), 0) 
+func (repo *TransactionRepository) GetTransactionByID(ctx context.Context, id int) (*models.Transaction, error) {
+	query := `
+		SELECT id, gross_amount, discount_amount, service_charge, tax_amount, tax_inclusive, total_amount, paid_amount, change_amount, shift_id, terminal_id, status, cancelled_at, COALESCE(cancelled_by, ''), COALESCE(cancel_reason, ''), created_at
 		FROM transactions
-		WHERE DATE(created_at) = CURRENT_DATE`).Scan(&totalRevenue, &totalTransaksi)
+		WHERE id = $1
+	`
+	var t models.Transaction
+	err := repo.db.QueryRowContext(ctx, query, id).Scan(
+		&t.ID,
+		&t.GrossAmount,
+		&t.DiscountAmount,
+		&t.ServiceCharge,
+		&t.TaxAmount,
+		&t.TaxInclusive,
+		&t.TotalAmount,
+		&t.PaidAmount,
+		&t.ChangeAmount,
+		&t.ShiftID,
+		&t.TerminalID,
+		&t.Status,
+		&t.CancelledAt,
+		&t.CancelledBy,
+		&t.CancelReason,
+		&t.CreatedAt,
+	)
 	if err != nil {
 		return nil, err
 	}
 
-	var productName string
-	var qtyTerjual int
-	err = repo.db.QueryRow(`
-		SELECT p.name, COALESCE(SUM(td.quantity), 0) AS total_qty
+	rows, err := repo.db.QueryContext(ctx, `
+		SELECT
+			td.id,
+			td.transaction_id,
+			COALESCE(td.product_id, 0),
+			td.product_name,
+			td.category_id,
+			td.category_name,
+			td.unit_price,
+			td.unit_cost,
+			td.quantity,
+			td.discount,
+			td.subtotal,
+			td.service_charge,
+			td.tax_rate,
+			td.tax_base,
+			td.tax_amount,
+			td.total
 		FROM transaction_details td
-		JOIN products p ON p.id = td.product_id
-		JOIN transactions t ON t.id = td.transaction_id
-		WHERE DATE(t.created_at) = CURRENT_DATE
-		GROUP BY p.id, p.name
-		ORDER BY total_qty DESC
-		LIMIT 1
-	`).Scan(&productName, &qtyTerjual)
+		WHERE td.transaction_id = $1
+		ORDER BY td.id
+	`, id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			productName = ""
-			qtyTerjual = 0
-		} else {
+		return nil, err
+	}
+	defer rows.Close()
+
+	t.Details = make([]models.TransactionDetail, 0)
+	for rows.Next() {
+		var d models.TransactionDetail
+		err := rows.Scan(
+			&d.ID,
+			&d.TransactionID,
+			&d.ProductID,
+			&d.ProductName,
+			&d.CategoryID,
+			&d.CategoryName,
+			&d.UnitPrice,
+			&d.UnitCost,
+			&d.Quantity,
+			&d.Discount,
+			&d.Subtotal,
+			&d.ServiceCharge,
+			&d.TaxRate,
+			&d.TaxBase,
+			&d.TaxAmount,
+			&d.Total,
+		)
+		if err != nil {
 			return nil, err
 		}
+		t.Details = append(t.Details, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return &models.TransactionReport{
-		TotalRevenue:   totalRevenue,
-		TotalTransaksi: totalTransaksi,
-		ProdukTerlaris: models.BestSellProduct{
-			Nama:       productName,
-			QtyTerjual: qtyTerjual,
-		},
-	}, nil
+	paymentRows, err := repo.db.QueryContext(ctx, `
+		SELECT id, transaction_id, method, amount, tendered, reference, created_at
+		FROM transaction_payments
+		WHERE transaction_id = $1
+		ORDER BY id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer paymentRows.Close()
+
+	t.Payments = make([]models.TransactionPayment, 0)
+	for paymentRows.Next() {
+		var p models.TransactionPayment
+		err := paymentRows.Scan(&p.ID, &p.TransactionID, &p.Method, &p.Amount, &p.Tendered, &p.Reference, &p.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		t.Payments = append(t.Payments, p)
+	}
+	if err := paymentRows.Err(); err != nil {
+		return nil, err
+	}
+
+	promotionRows, err := repo.db.QueryContext(ctx, `
+		SELECT promotion_id, promotion_name, amount
+		FROM transaction_promotions
+		WHERE transaction_id = $1
+		ORDER BY id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer promotionRows.Close()
+
+	t.Promotions = make([]models.AppliedPromotion, 0)
+	for promotionRows.Next() {
+		var ap models.AppliedPromotion
+		if err := promotionRows.Scan(&ap.PromotionID, &ap.Name, &ap.Amount); err != nil {
+			return nil, err
+		}
+		t.Promotions = append(t.Promotions, ap)
+	}
+	if err := promotionRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &t, nil
 }
 
-func (repo *TransactionRepository) GetReportByDateRange(startDate, endDate string) (*models.TransactionReport, error) {
-	var totalRevenue int
+func (repo *TransactionRepository) GetTransactions(ctx context.Context, filter models.TransactionFilter) ([]models.Transaction, int, error) {
+	conditions := make([]string, 0)
+	args := make([]interface{}, 0)
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.StartDate != "" {
+		addCondition("t.created_at >= $%d", filter.StartDate)
+	}
+	if filter.EndDate != "" {
+		addCondition("t.created_at <= $%d", filter.EndDate)
+	}
+	if filter.MinAmount != nil {
+		addCondition("t.total_amount >= $%d", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		addCondition("t.total_amount <= $%d", *filter.MaxAmount)
+	}
+	if filter.ProductID != 0 {
+		addCondition("EXISTS (SELECT 1 FROM transaction_details td WHERE td.transaction_id = t.id AND td.product_id = $%d)", filter.ProductID)
+	}
+	if filter.PaymentMethod != "" {
+		addCondition("EXISTS (SELECT 1 FROM transaction_payments tp WHERE tp.transaction_id = t.id AND tp.method = $%d)", filter.PaymentMethod)
+	}
+	if filter.Status != "" {
+		addCondition("t.status = $%d", filter.Status)
+	}
+	if filter.ShiftID != 0 {
+		addCondition("t.shift_id = $%d", filter.ShiftID)
+	}
+	if filter.TerminalID != 0 {
+		addCondition("t.terminal_id = $%d", filter.TerminalID)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := repo.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM transactions t"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT t.id, t.gross_amount, t.discount_amount, t.service_charge, t.tax_amount, t.tax_inclusive, t.total_amount, t.paid_amount, t.change_amount, t.shift_id, t.terminal_id, t.status, t.cancelled_at, COALESCE(t.cancelled_by, ''), COALESCE(t.cancel_reason, ''), t.created_at
+		FROM transactions t` + where + fmt.Sprintf(`
+		ORDER BY t.created_at DESC, t.id DESC
+		LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+
+	rows, err := repo.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	transactions := make([]models.Transaction, 0)
+	for rows.Next() {
+		var t models.Transaction
+		err := rows.Scan(
+			&t.ID,
+			&t.GrossAmount,
+			&t.DiscountAmount,
+			&t.ServiceCharge,
+			&t.TaxAmount,
+			&t.TaxInclusive,
+			&t.TotalAmount,
+			&t.PaidAmount,
+			&t.ChangeAmount,
+			&t.ShiftID,
+			&t.TerminalID,
+			&t.Status,
+			&t.CancelledAt,
+			&t.CancelledBy,
+			&t.CancelReason,
+			&t.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		transactions = append(transactions, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return transactions, total, nil
+}
+
+// CancelTransaction moves a completed transaction to the voided or refunded
+// status and puts the sold quantities back into stock.
+//
+// A void takes the sale back as if it never happened, so it is only allowed
+// while the sale has no returns and its shift is still open. A refund gives
+// back what is left of the sale and is recorded as a full_refund return,
+// paid out like any other return.
+func (repo *TransactionRepository) CancelTransaction(ctx context.Context, id int, status string, req models.CancelTransactionRequest) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var current string
+	var shiftID sql.NullInt64
+	err = tx.QueryRowContext(ctx, "SELECT status, shift_id FROM transactions WHERE id = $1 FOR UPDATE", id).Scan(&current, &shiftID)
+	if err != nil {
+		return err
+	}
+	if current != models.TransactionStatusCompleted {
+		return models.ErrTransactionNotCompleted
+	}
+
+	if status == models.TransactionStatusVoided {
+		var hasReturns bool
+		err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM returns WHERE transaction_id = $1)", id).Scan(&hasReturns)
+		if err != nil {
+			return err
+		}
+		if hasReturns {
+			return models.ErrTransactionHasReturns
+		}
+		// The share lock keeps the shift from being closed until the void
+		// commits.
+		if shiftID.Valid {
+			var shiftStatus string
+			err := tx.QueryRowContext(ctx, "SELECT status FROM shifts WHERE id = $1 FOR SHARE", shiftID.Int64).Scan(&shiftStatus)
+			if err != nil {
+				return err
+			}
+			if shiftStatus != "open" {
+				return models.ErrVoidShiftClosed
+			}
+		}
+	}
+
+	var refund []models.ReturnDetail
+	refundAmount := 0
+	if status == models.TransactionStatusRefunded {
+		lines, ids, err := loadReturnableLines(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		for _, detailID := range ids {
+			l := lines[detailID]
+			if l.quantity == l.returnedQty {
+				continue
+			}
+			refund = append(refund, models.ReturnDetail{
+				TransactionDetailID: detailID,
+				ProductID:           l.productID,
+				Quantity:            l.quantity - l.returnedQty,
+				Amount:              l.total - l.returnedAmount,
+				TaxAmount:           l.taxAmount - l.returnedTax,
+			})
+			refundAmount += l.total - l.returnedAmount
+		}
+	}
+
+	// Lock the products in ID order, the same order checkout uses, before
+	// putting the quantities back.
+	_, err = tx.ExecContext(ctx, `
+		SELECT id FROM products
+		WHERE id IN (SELECT product_id FROM transaction_details WHERE transaction_id = $1)
+		ORDER BY id
+		FOR UPDATE
+	`, id)
+	if err != nil {
+		return err
+	}
+	// Quantities already brought back through partial returns were restocked
+	// at that time and are not added again.
+	_, err = tx.ExecContext(ctx, `
+		UPDATE products p
+		SET stock = p.stock + d.quantity
+		FROM (
+			SELECT td.product_id, SUM(td.quantity - COALESCE(rd.quantity, 0)) AS quantity
+			FROM transaction_details td
+			LEFT JOIN (
+				SELECT transaction_detail_id, SUM(quantity) AS quantity
+				FROM return_details
+				GROUP BY transaction_detail_id
+			) rd ON rd.transaction_detail_id = td.id
+			WHERE td.transaction_id = $1
+			GROUP BY td.product_id
+		) d
+		WHERE p.id = d.product_id
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	// The refund is recorded after restocking, which counts only what had
+	// been returned before it.
+	if len(refund) > 0 {
+		ret := &models.Return{
+			TransactionID: id,
+			TotalAmount:   refundAmount,
+			FullRefund:    true,
+			RefundMethod:  req.RefundMethod,
+			Reason:        req.Reason,
+			PerformedBy:   req.PerformedBy,
+		}
+		if err := insertReturn(ctx, tx, ret, refund, req.ShiftID, repo.requireShift); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE transactions SET status = $1, cancelled_at = NOW(), cancelled_by = $2, cancel_reason = $3 WHERE id = $4",
+		status, req.PerformedBy, req.Reason, id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (repo *TransactionRepository) GetReport(ctx context.Context) (*models.TransactionReport, error) {
+	return repo.buildReport(ctx, "DATE(%[1]s) = CURRENT_DATE")
+}
+
+func (repo *TransactionRepository) GetReportByDateRange(ctx context.Context, startDate, endDate string) (*models.TransactionReport, error) {
+	return repo.buildReport(ctx, "%[1]s >= $1 AND %[1]s <= $2", startDate, endDate)
+}
+
+// GetTaxSummary totals tax and service charge per tax rate. With empty dates
+// it covers today.
+func (repo *TransactionRepository) GetTaxSummary(ctx context.Context, startDate, endDate string) (*models.TaxSummary, error) {
+	filter := "DATE(%[1]s) = CURRENT_DATE"
+	args := []interface{}{}
+	if startDate != "" && endDate != "" {
+		filter = "%[1]s >= $1 AND %[1]s <= $2"
+		args = append(args, startDate, endDate)
+	}
+
+	rows, err := repo.db.QueryContext(ctx, `
+		SELECT s.tax_rate, SUM(s.tax_base), SUM(s.tax_amount), SUM(s.service_charge), SUM(s.returned_tax)
+		FROM (
+			SELECT td.tax_rate, td.tax_base, td.tax_amount, td.service_charge, 0 AS returned_tax
+			FROM transaction_details td
+			JOIN transactions t ON t.id = td.transaction_id
+			WHERE t.status <> 'voided' AND `+fmt.Sprintf(filter, "t.created_at")+`
+			UNION ALL
+			SELECT td.tax_rate, 0, 0, 0, rd.tax_amount
+			FROM return_details rd
+			JOIN transaction_details td ON td.id = rd.transaction_detail_id
+			JOIN returns r ON r.id = rd.return_id
+			JOIN transactions t ON t.id = r.transaction_id
+			WHERE t.status <> 'voided' AND `+fmt.Sprintf(filter, "r.created_at")+`
+		) s
+		GROUP BY s.tax_rate
+		ORDER BY s.tax_rate
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summary := &models.TaxSummary{Rates: make([]models.TaxSummaryLine, 0)}
+	for rows.Next() {
+		var l models.TaxSummaryLine
+		if err := rows.Scan(&l.TaxRate, &l.TaxBase, &l.TaxAmount, &l.ServiceCharge, &l.ReturnedTax); err != nil {
+			return nil, err
+		}
+		l.TaxAmount -= l.ReturnedTax
+		summary.TotalTaxBase += l.TaxBase
+		summary.TotalTaxAmount += l.TaxAmount
+		summary.TotalServiceCharge += l.ServiceCharge
+		summary.Rates = append(summary.Rates, l)
+	}
+
+	return summary, rows.Err()
+}
+
+// buildReport summarizes the period described by filter, a format string
+// whose %[1]s is replaced with the timestamp column being filtered. Voided
+// and refunded sales are listed separately, and voided ones are left out of
+// revenue and best sellers. Returns and refunds are netted out on the day
+// they were made.
+func (repo *TransactionRepository) buildReport(ctx context.Context, filter string, args ...interface{}) (*models.TransactionReport, error) {
+	saleFilter := fmt.Sprintf(filter, "t.created_at")
+	returnFilter := fmt.Sprintf(filter, "r.created_at")
+
+	var salesRevenue int
 	var totalTransaksi int
+	var totalDiskon int
+	var salesTax int
+	var totalService int
 
-	err := repo.db.QueryRow(`
-		SELECT COALESCE(SUM(total_amount), 0), COALESCE(COUNT(*), 0)
-		FROM transactions
-		WHERE created_at >= $1 AND created_at <= $2`, 
-		startDate, endDate).Scan(&totalRevenue, &totalTransaksi)
+	err := repo.db.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(SUM(t.total_amount), 0),
+			COALESCE(COUNT(*), 0),
+			COALESCE(SUM(t.discount_amount), 0),
+			COALESCE(SUM(t.tax_amount), 0),
+			COALESCE(SUM(t.service_charge), 0)
+		FROM transactions t
+		WHERE t.status <> 'voided' AND `+saleFilter,
+		args...).Scan(&salesRevenue, &totalTransaksi, &totalDiskon, &salesTax, &totalService)
 	if err != nil {
 		return nil, err
 	}
 
+	var totalRetur int
+	var totalRefund int
+	var returnedTax int
+	err = repo.db.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(SUM(r.total_amount) FILTER (WHERE NOT r.full_refund), 0),
+			COALESCE(SUM(r.total_amount) FILTER (WHERE r.full_refund), 0),
+			COALESCE(SUM(rd.tax_amount), 0)
+		FROM returns r
+		JOIN transactions t ON t.id = r.transaction_id
+		LEFT JOIN (
+			SELECT return_id, SUM(tax_amount) AS tax_amount
+			FROM return_details
+			GROUP BY return_id
+		) rd ON rd.return_id = r.id
+		WHERE t.status <> 'voided' AND `+returnFilter,
+		args...).Scan(&totalRetur, &totalRefund, &returnedTax)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sales are grouped by product, falling back to the snapshot name for
+	// products that have since been deleted, and shown under the most recent
+	// name they were sold with.
 	var productName string
 	var qtyTerjual int
-	err = repo.db.QueryRow(`
-		SELECT p.name, COALESCE(SUM(td.quantity), 0) AS total_qty
-		FROM transaction_details td
-		JOIN products p ON p.id = td.product_id
-		JOIN transactions t ON t.id = td.transaction_id
-		WHERE t.created_at >= $1 AND t.created_at <= $2
-		GROUP BY p.id, p.name
+	err = repo.db.QueryRowContext(ctx, `
+		SELECT (ARRAY_AGG(s.product_name ORDER BY s.sold_at DESC))[1], COALESCE(SUM(s.quantity), 0) AS total_qty
+		FROM (
+			SELECT COALESCE(td.product_id::text, td.product_name) AS product_key, td.product_name, td.quantity, t.created_at AS sold_at
+			FROM transaction_details td
+			JOIN transactions t ON t.id = td.transaction_id
+			WHERE t.status <> 'voided' AND `+saleFilter+`
+			UNION ALL
+			SELECT COALESCE(td.product_id::text, td.product_name), td.product_name, -rd.quantity, t.created_at
+			FROM return_details rd
+			JOIN transaction_details td ON td.id = rd.transaction_detail_id
+			JOIN returns r ON r.id = rd.return_id
+			JOIN transactions t ON t.id = r.transaction_id
+			WHERE t.status <> 'voided' AND `+returnFilter+`
+		) s
+		GROUP BY s.product_key
 		ORDER BY total_qty DESC
 		LIMIT 1
-	`, startDate, endDate).Scan(&productName, &qtyTerjual)
+	`, args...).Scan(&productName, &qtyTerjual)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			productName = ""
@@ -170,12 +1016,68 @@ func (repo *TransactionRepository) GetReportByDateRange(startDate, endDate strin
 		}
 	}
 
+	methodRows, err := repo.db.QueryContext(ctx, `
+		SELECT tp.method, COALESCE(SUM(tp.amount), 0), COUNT(DISTINCT tp.transaction_id)
+		FROM transaction_payments tp
+		JOIN transactions t ON t.id = tp.transaction_id
+		WHERE t.status <> 'voided' AND `+saleFilter+`
+		GROUP BY tp.method
+		ORDER BY tp.method
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer methodRows.Close()
+
+	methods := make([]models.PaymentMethodSummary, 0)
+	for methodRows.Next() {
+		var m models.PaymentMethodSummary
+		if err := methodRows.Scan(&m.Method, &m.TotalAmount, &m.TotalTransaksi); err != nil {
+			return nil, err
+		}
+		methods = append(methods, m)
+	}
+	if err := methodRows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err := repo.db.QueryContext(ctx, `
+		SELECT t.id, t.status, t.total_amount, t.cancelled_at, COALESCE(t.cancelled_by, ''), COALESCE(t.cancel_reason, ''), t.created_at
+		FROM transactions t
+		WHERE t.status <> 'completed' AND `+saleFilter+`
+		ORDER BY t.cancelled_at
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cancelled := make([]models.CancelledTransaction, 0)
+	for rows.Next() {
+		var ct models.CancelledTransaction
+		err := rows.Scan(&ct.ID, &ct.Status, &ct.TotalAmount, &ct.CancelledAt, &ct.CancelledBy, &ct.CancelReason, &ct.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		cancelled = append(cancelled, ct)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
 	return &models.TransactionReport{
-		TotalRevenue:   totalRevenue,
-		TotalTransaksi: totalTransaksi,
+		TotalRevenue:     salesRevenue - totalRetur - totalRefund,
+		TotalTransaksi:   totalTransaksi,
+		TotalRetur:       totalRetur,
+		TotalRefund:      totalRefund,
+		TotalDiskon:      totalDiskon,
+		TotalPajak:       salesTax - returnedTax,
+		TotalService:     totalService,
 		ProdukTerlaris: models.BestSellProduct{
 			Nama:       productName,
 			QtyTerjual: qtyTerjual,
 		},
+		MetodePembayaran: methods,
+		TransaksiBatal:   cancelled,
 	}, nil
 }
@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"errors"
+	"kasir-api/models"
+	"reflect"
+	"testing"
+)
+
+func TestAllocatePayments(t *testing.T) {
+	cash := func(amount int) models.CheckoutPayment {
+		return models.CheckoutPayment{Method: models.PaymentMethodCash, Amount: amount}
+	}
+	qris := func(amount int) models.CheckoutPayment {
+		return models.CheckoutPayment{Method: "qris", Amount: amount}
+	}
+
+	tests := []struct {
+		name     string
+		total    int
+		payments []models.CheckoutPayment
+		want     []models.TransactionPayment
+		paid     int
+		change   int
+		wantErr  error
+	}{
+		{
+			name:     "cash with change",
+			total:    25000,
+			payments: []models.CheckoutPayment{cash(50000)},
+			want:     []models.TransactionPayment{{Method: models.PaymentMethodCash, Amount: 25000, Tendered: 50000}},
+			paid:     50000,
+			change:   25000,
+		},
+		{
+			name:     "split with change from cash",
+			total:    25000,
+			payments: []models.CheckoutPayment{cash(20000), qris(10000)},
+			want: []models.TransactionPayment{
+				{Method: models.PaymentMethodCash, Amount: 15000, Tendered: 20000},
+				{Method: "qris", Amount: 10000, Tendered: 10000},
+			},
+			paid:   30000,
+			change: 5000,
+		},
+		{
+			name:     "insufficient",
+			total:    25000,
+			payments: []models.CheckoutPayment{cash(20000)},
+			wantErr:  models.ErrInsufficientPayment,
+		},
+		{
+			name:    "no payments",
+			total:   25000,
+			wantErr: models.ErrInsufficientPayment,
+		},
+		{
+			name:     "non-cash over the total",
+			total:    25000,
+			payments: []models.CheckoutPayment{qris(30000)},
+			wantErr:  models.ErrNonCashOverpayment,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, paid, change, err := allocatePayments(tt.total, tt.payments)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("allocatePayments error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("allocatePayments: %v", err)
+			}
+			if !reflect.DeepEqual(lines, tt.want) || paid != tt.paid || change != tt.change {
+				t.Errorf("allocatePayments = %+v, paid %d, change %d; want %+v, paid %d, change %d", lines, paid, change, tt.want, tt.paid, tt.change)
+			}
+		})
+	}
+}
+
+func TestPickOpenShift(t *testing.T) {
+	tests := []struct {
+		name         string
+		open         []int
+		requireShift bool
+		want         int
+		wantErr      error
+	}{
+		{name: "no shift open", open: []int{}},
+		{name: "no shift open but required", open: []int{}, requireShift: true, wantErr: models.ErrNoOpenShift},
+		{name: "one shift open", open: []int{7}, want: 7},
+		{name: "one shift open and required", open: []int{7}, requireShift: true, want: 7},
+		{name: "several shifts open", open: []int{7, 9}, wantErr: models.ErrShiftRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pickOpenShift(tt.open, tt.requireShift)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("pickOpenShift error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("pickOpenShift: %v", err)
+			}
+			if tt.want == 0 {
+				if got != nil {
+					t.Errorf("pickOpenShift = %d, want no shift", *got)
+				}
+				return
+			}
+			if got == nil || *got != tt.want {
+				t.Errorf("pickOpenShift = %v, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"kasir-api/models"
+	"time"
+)
+
+type UserRepository struct {
+	db *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+const userColumns = `
+	id, username, password_hash, role, active, created_at, updated_at`
+
+func scanUser(row interface{ Scan(...interface{}) error }, u *models.User) error {
+	return row.Scan(
+		&u.ID,
+		&u.Username,
+		&u.PasswordHash,
+		&u.Role,
+		&u.Active,
+		&u.CreatedAt,
+		&u.UpdatedAt,
+	)
+}
+
+func (repo *UserRepository) Create(ctx context.Context, u *models.User) error {
+	row := repo.db.QueryRowContext(ctx, `
+		INSERT INTO users (username, password_hash, role, active) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (username) DO NOTHING
+		RETURNING`+userColumns,
+		u.Username, u.PasswordHash, u.Role, u.Active,
+	)
+	err := scanUser(row, u)
+	if err == sql.ErrNoRows {
+		return models.ErrUsernameTaken
+	}
+	return err
+}
+
+func (repo *UserRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
+	var u models.User
+	row := repo.db.QueryRowContext(ctx, "SELECT"+userColumns+" FROM users WHERE id = $1", id)
+	if err := scanUser(row, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (repo *UserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	var u models.User
+	row := repo.db.QueryRowContext(ctx, "SELECT"+userColumns+" FROM users WHERE username = $1", username)
+	if err := scanUser(row, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (repo *UserRepository) GetAll(ctx context.Context) ([]models.User, error) {
+	rows, err := repo.db.QueryContext(ctx, "SELECT"+userColumns+" FROM users ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]models.User, 0)
+	for rows.Next() {
+		var u models.User
+		if err := scanUser(rows, &u); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+func (repo *UserRepository) Count(ctx context.Context) (int, error) {
+	var count int
+	err := repo.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&count)
+	return count, err
+}
+
+// Update saves the password hash, role and active flag of u. Deactivating a
+// user also revokes their refresh tokens.
+func (repo *UserRepository) Update(ctx context.Context, u *models.User) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRowContext(ctx, `
+		UPDATE users SET password_hash = $1, role = $2, active = $3, updated_at = NOW()
+		WHERE id = $4
+		RETURNING`+userColumns,
+		u.PasswordHash, u.Role, u.Active, u.ID,
+	)
+	if err := scanUser(row, u); err != nil {
+		return err
+	}
+
+	if !u.Active {
+		_, err = tx.ExecContext(ctx, "UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL", u.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// CreateRefreshToken stores the token hash valid for ttl and returns when it
+// expires. Expiry is computed and checked by the database clock.
+func (repo *UserRepository) CreateRefreshToken(ctx context.Context, userID int, tokenHash string, ttl time.Duration) (time.Time, error) {
+	var expiresAt time.Time
+	err := repo.db.QueryRowContext(ctx,
+		"INSERT INTO refresh_tokens (user_id, token_hash, expires_at) VALUES ($1, $2, NOW() + make_interval(secs => $3)) RETURNING expires_at",
+		userID, tokenHash, ttl.Seconds(),
+	).Scan(&expiresAt)
+	return expiresAt, err
+}
+
+// UseRefreshToken revokes the refresh token and returns its active user.
+// Presenting a token that was already revoked means it has leaked, so every
+// other token of that user is revoked too.
+func (repo *UserRepository) UseRefreshToken(ctx context.Context, tokenHash string) (*models.User, error) {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var userID int
+	var expired bool
+	var revokedAt *time.Time
+	err = tx.QueryRowContext(ctx,
+		"SELECT user_id, expires_at <= NOW(), revoked_at FROM refresh_tokens WHERE token_hash = $1 FOR UPDATE",
+		tokenHash,
+	).Scan(&userID, &expired, &revokedAt)
+	if err == sql.ErrNoRows {
+		return nil, models.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if revokedAt != nil {
+		_, err = tx.ExecContext(ctx, "UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL", userID)
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return nil, models.ErrInvalidToken
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE refresh_tokens SET revoked_at = NOW() WHERE token_hash = $1", tokenHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var u models.User
+	row := tx.QueryRowContext(ctx, "SELECT"+userColumns+" FROM users WHERE id = $1", userID)
+	if err := scanUser(row, &u); err != nil {
+		return nil, err
+	}
+	if !u.Active || expired {
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return nil, models.ErrInvalidToken
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (repo *UserRepository) RevokeRefreshToken(ctx context.Context, tokenHash string) error {
+	_, err := repo.db.ExecContext(ctx, "UPDATE refresh_tokens SET revoked_at = NOW() WHERE token_hash = $1 AND revoked_at IS NULL", tokenHash)
+	return err
+}
@@ -2,26 +2,108 @@ package routes
 
 import (
 	"database/sql"
+	"kasir-api/auth"
+	"kasir-api/barcode"
+	"kasir-api/config"
 	"kasir-api/handlers"
+	"kasir-api/middleware"
+	"kasir-api/models"
+	"kasir-api/pricing"
+	"kasir-api/receipt"
 	"kasir-api/repositories"
 	"kasir-api/services"
+	"log"
 
 	"github.com/gin-gonic/gin"
 )
 
-func Routes(r *gin.Engine, db *sql.DB) {
+// Workers are the background jobs behind the routes. Routes only builds
+// them: the server starts them, and stops them once it has stopped taking
+// requests.
+type Workers struct {
+	print *services.PrintService
+	keys  *services.IdempotencyKeyCleaner
+}
+
+func (w *Workers) Start() {
+	w.print.Start()
+	w.keys.Start()
+}
+
+func (w *Workers) Stop() {
+	w.print.Stop()
+	w.keys.Stop()
+}
+
+// Routes registers every handler on r and returns the workers they rely on,
+// not yet started. It does not touch the database; cfg.JWTSecret must be set.
+func Routes(r *gin.Engine, db *sql.DB, cfg *config.Config) *Workers {
+	// Audit log
+	auditRepo := repositories.NewAuditRepository(db)
+	auditService := services.NewAuditService(auditRepo, cfg.RequestTimeout)
+	audit := handlers.NewAuditHandler(auditService)
 	// Category
 	categoryRepo := repositories.NewCategoryRepository(db)
 	categoryService := services.NewCategoryService(categoryRepo)
-	category := handlers.NewCategoryHandler(categoryService)
+	category := handlers.NewCategoryHandler(categoryService, auditService)
 	// Products
+	scaleFormats, err := barcode.ParseFormats(cfg.ScaleBarcodeFormats)
+	if err != nil {
+		log.Fatal("Invalid SCALE_BARCODE_FORMATS:", err)
+	}
 	productRepo := repositories.NewProductRepository(db)
-	productService := services.NewProductService(productRepo)
-	product := handlers.NewProductHandler(productService)
+	productService := services.NewProductService(productRepo, categoryRepo, scaleFormats)
+	product := handlers.NewProductHandler(productService, auditService)
+	// Promotions
+	promotionRepo := repositories.NewPromotionRepository(db)
+	promotionService := services.NewPromotionService(promotionRepo)
+	promotion := handlers.NewPromotionHandler(promotionService, auditService)
 	// Transaction
-	transactionRepo := repositories.NewTransactionRepository(db)
-	transactionService := services.NewTransactionService(transactionRepo)
-	transaction := handlers.NewTransactionHandler(transactionService)
+	transactionRepo := repositories.NewTransactionRepository(db, cfg.CheckoutRequireShift)
+	tax := pricing.NewTaxConfig(cfg.TaxRate, cfg.TaxInclusive, cfg.ServiceChargeRate)
+	transactionService := services.NewTransactionService(transactionRepo, promotionRepo, tax, scaleFormats, cfg.IdempotencyKeyTTL)
+	keyCleaner := services.NewIdempotencyKeyCleaner(transactionRepo, cfg.IdempotencyKeyTTL, cfg.IdempotencyCleanupInterval)
+	store := receipt.Store{
+		Name:    cfg.StoreName,
+		Address: cfg.StoreAddress,
+		Phone:   cfg.StorePhone,
+		Footer:  cfg.ReceiptFooter,
+	}
+	receiptHandler := handlers.NewReceiptHandler(transactionService, store, cfg.ReceiptPaperWidth)
+	// Printing
+	printJobRepo := repositories.NewPrintJobRepository(db)
+	printService := services.NewPrintService(printJobRepo, transactionRepo, services.PrintConfig{
+		DefaultAddress: cfg.PrinterAddress,
+		Allowed:        cfg.PrinterAllowedAddresses,
+		AutoPrint:      cfg.PrinterAutoPrint,
+		MaxAttempts:    cfg.PrinterMaxAttempts,
+		RetryInterval:  cfg.PrinterRetryInterval,
+		Timeout:        cfg.PrinterTimeout,
+		QueryTimeout:   cfg.RequestTimeout,
+		Paper:          cfg.ReceiptPaperWidth,
+		Store:          store,
+	})
+	printHandler := handlers.NewPrintHandler(printService, auditService)
+	transaction := handlers.NewTransactionHandler(transactionService, printService, auditService, cfg.CheckoutRowLock)
+	// Shifts
+	shiftRepo := repositories.NewShiftRepository(db)
+	shiftService := services.NewShiftService(shiftRepo)
+	shift := handlers.NewShiftHandler(shiftService, auditService, store, cfg.ReceiptPaperWidth)
+	// Users and authentication
+	tokens := auth.NewTokenManager([]byte(cfg.JWTSecret), cfg.AccessTokenTTL)
+	userRepo := repositories.NewUserRepository(db)
+	userService := services.NewUserService(userRepo)
+	authService := services.NewAuthService(userRepo, tokens, cfg.RefreshTokenTTL)
+	authHandler := handlers.NewAuthHandler(authService, userService)
+	user := handlers.NewUserHandler(userService, auditService)
+	// Terminals
+	terminalRepo := repositories.NewTerminalRepository(db)
+	terminalService := services.NewTerminalService(terminalRepo)
+	terminal := handlers.NewTerminalHandler(terminalService, auditService)
+	// Returns
+	returnRepo := repositories.NewReturnRepository(db, cfg.CheckoutRequireShift)
+	returnService := services.NewReturnService(returnRepo)
+	returnHandler := handlers.NewReturnHandler(returnService, auditService)
 
 	r.GET("/", func(c *gin.Context){
 		c.JSON(200, gin.H{
@@ -30,24 +112,101 @@ func Routes(r *gin.Engine, db *sql.DB) {
 		})
 	})
 
-	api := r.Group("/api/")
+	// Every API request is bounded by RequestTimeout; checkout and reports
+	// override it below.
+	api := r.Group("/api/", middleware.Timeout(cfg.RequestTimeout))
+	{
+		authGroup := api.Group("/auth")
+		authGroup.POST("/login", authHandler.Login)
+		authGroup.POST("/refresh", authHandler.Refresh)
+		authGroup.POST("/logout", authHandler.Logout)
+	}
+
+	// Every other route needs a logged in user or a terminal key, and the
+	// permission for the route. See models.RolePermissions for what each
+	// user role may do.
+	catalogRead := middleware.RequirePermission(models.PermissionCatalogRead)
+	catalogWrite := middleware.RequirePermission(models.PermissionCatalogWrite)
+	transactionsRead := middleware.RequirePermission(models.PermissionTransactionsRead)
+	transactionsCancel := middleware.RequirePermission(models.PermissionTransactionsCancel)
+	printing := middleware.RequirePermission(models.PermissionPrint)
+
+	secured := api.Group("/", middleware.Authenticate(tokens, userService, terminalService))
 	{
-		categoryGroup := api.Group("/category")
-		categoryGroup.GET("/", category.GetAll)
-		categoryGroup.POST("/", category.Create)
-		categoryGroup.GET("/:id", category.GetByID)
-		categoryGroup.PUT("/:id", category.Update)
-		categoryGroup.DELETE("/:id", category.Delete)
-
-		productGroup := api.Group("/product")
-		productGroup.GET("/", product.GetAll)
-		productGroup.POST("/", product.Create)
-		productGroup.GET("/:id", product.GetByID)
-		productGroup.PUT("/:id", product.Update)
-		productGroup.DELETE("/:id", product.Delete)
-
-		api.POST("checkout", transaction.Checkout)
-		api.GET("/report/hari-ini", transaction.GetReport)
-		api.GET("/report", transaction.GetReportByDateRange)
+		secured.GET("/auth/me", authHandler.Me)
+
+		userGroup := secured.Group("/users", middleware.RequirePermission(models.PermissionUsers))
+		userGroup.GET("/", user.GetAll)
+		userGroup.POST("/", user.Create)
+		userGroup.PUT("/:id", user.Update)
+
+		terminalGroup := secured.Group("/terminals", middleware.RequirePermission(models.PermissionTerminals))
+		terminalGroup.GET("/", terminal.GetAll)
+		terminalGroup.POST("/", terminal.Create)
+		terminalGroup.GET("/:id", terminal.GetByID)
+		terminalGroup.PUT("/:id", terminal.Update)
+		terminalGroup.GET("/:id/keys", terminal.GetAPIKeys)
+		terminalGroup.POST("/:id/keys", terminal.IssueAPIKey)
+		terminalGroup.DELETE("/:id/keys/:keyId", terminal.RevokeAPIKey)
+
+		categoryGroup := secured.Group("/category")
+		categoryGroup.GET("/", catalogRead, category.GetAll)
+		categoryGroup.POST("/", catalogWrite, category.Create)
+		categoryGroup.GET("/:id", catalogRead, category.GetByID)
+		categoryGroup.PUT("/:id", catalogWrite, category.Update)
+		categoryGroup.DELETE("/:id", catalogWrite, category.Delete)
+
+		productGroup := secured.Group("/product")
+		productGroup.GET("/", catalogRead, product.GetAll)
+		productGroup.POST("/", catalogWrite, product.Create)
+		productGroup.GET("/barcode/:code", catalogRead, product.GetByBarcode)
+		productGroup.GET("/:id", catalogRead, product.GetByID)
+		productGroup.PUT("/:id", catalogWrite, product.Update)
+		productGroup.DELETE("/:id", catalogWrite, product.Delete)
+
+		promotionGroup := secured.Group("/promotion")
+		promotionGroup.GET("/", catalogRead, promotion.GetAll)
+		promotionGroup.POST("/", catalogWrite, promotion.Create)
+		promotionGroup.GET("/:id", catalogRead, promotion.GetByID)
+		promotionGroup.PUT("/:id", catalogWrite, promotion.Update)
+		promotionGroup.DELETE("/:id", catalogWrite, promotion.Delete)
+
+		shiftGroup := secured.Group("/shifts", middleware.RequirePermission(models.PermissionShifts))
+		shiftGroup.GET("/", shift.GetAll)
+		shiftGroup.POST("/open", shift.Open)
+		shiftGroup.GET("/:id", shift.GetByID)
+		shiftGroup.POST("/:id/cash-movements", shift.AddCashMovement)
+		shiftGroup.POST("/:id/close", shift.Close)
+		shiftGroup.GET("/:id/summary", shift.GetSummary)
+
+		secured.POST("checkout", middleware.Timeout(cfg.CheckoutTimeout), middleware.RequirePermission(models.PermissionCheckout), transaction.Checkout)
+		secured.GET("/transactions", transactionsRead, transaction.GetAll)
+		secured.GET("/transactions/:id", transactionsRead, transaction.GetByID)
+		secured.GET("/transactions/:id/receipt", transactionsRead, receiptHandler.GetReceipt)
+		secured.POST("/transactions/:id/print", printing, printHandler.Print)
+		secured.GET("/transactions/:id/print-jobs", printing, printHandler.GetJobsByTransactionID)
+		secured.GET("/print-jobs/:id", printing, printHandler.GetJob)
+		secured.POST("/transactions/:id/void", transactionsCancel, transaction.Void)
+		secured.POST("/transactions/:id/refund", transactionsCancel, transaction.Refund)
+		secured.POST("/transactions/:id/returns", transactionsCancel, returnHandler.Create)
+		secured.GET("/transactions/:id/returns", transactionsRead, returnHandler.GetByTransactionID)
+
+		secured.GET("/audit", middleware.RequirePermission(models.PermissionAudit), audit.GetAll)
+
+		reportGroup := secured.Group("/report", middleware.Timeout(cfg.ReportTimeout), middleware.RequirePermission(models.PermissionReports))
+		reportGroup.GET("/hari-ini", transaction.GetReport)
+		reportGroup.GET("", transaction.GetReportByDateRange)
+		reportGroup.GET("/pajak", transaction.GetTaxSummary)
+	}
+
+	// Version 2 of the reports uses English paths and field names. The
+	// /api/report routes above keep the original shape for existing clients.
+	reportsV2 := r.Group("/api/v2/reports", middleware.Timeout(cfg.ReportTimeout), middleware.Authenticate(tokens, userService, terminalService), middleware.RequirePermission(models.PermissionReports))
+	{
+		reportsV2.GET("/today", transaction.GetReportV2)
+		reportsV2.GET("", transaction.GetReportByDateRangeV2)
+		reportsV2.GET("/tax", transaction.GetTaxSummary)
 	}
+
+	return &Workers{print: printService, keys: keyCleaner}
 }
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"kasir-api/config"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"kasir-api/services"
+	"log"
+)
+
+// demoCatalog is loaded by `seed --demo`, grouped by category.
+var demoCatalog = []struct {
+	category models.CategoryRequest
+	products []models.ProductRequest
+}{
+	{
+		category: models.CategoryRequest{Name: "Makanan", Description: "Makanan ringan dan instan"},
+		products: []models.ProductRequest{
+			{Name: "Indomie Goreng", Price: 3500, Cost: 2800, Stock: 120},
+			{Name: "Roti Tawar", Price: 16000, Cost: 13000, Stock: 20},
+			{Name: "Keripik Kentang", Price: 12000, Cost: 9000, Stock: 40},
+		},
+	},
+	{
+		category: models.CategoryRequest{Name: "Minuman", Description: "Minuman dingin dan kemasan"},
+		products: []models.ProductRequest{
+			{Name: "Air Mineral 600ml", Price: 4000, Cost: 2500, Stock: 200},
+			{Name: "Teh Botol", Price: 5000, Cost: 3500, Stock: 100},
+			{Name: "Kopi Susu Kaleng", Price: 8000, Cost: 6000, Stock: 60},
+		},
+	},
+	{
+		category: models.CategoryRequest{Name: "Kebutuhan Rumah", Description: "Sabun, deterjen dan lainnya"},
+		products: []models.ProductRequest{
+			{Name: "Sabun Mandi", Price: 4500, Cost: 3200, Stock: 80},
+			{Name: "Deterjen 800g", Price: 22000, Cost: 18000, Stock: 30},
+		},
+	},
+}
+
+// runSeed handles `kasir-api seed`. It creates the owner account from
+// OWNER_USERNAME and OWNER_PASSWORD and, with --demo, a small catalog to
+// try the API with.
+func runSeed(cfg *config.Config, args []string) {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	demo := flags.Bool("demo", false, "load the demo catalog into an empty database")
+	flags.Parse(args)
+
+	db := openDB(cfg)
+	defer db.Close()
+	ctx := context.Background()
+
+	userService := services.NewUserService(repositories.NewUserRepository(db))
+	created, err := userService.EnsureOwner(ctx, cfg.OwnerUsername, cfg.OwnerPassword)
+	if err != nil {
+		log.Fatal("Failed to create owner account:", err)
+	}
+	if created {
+		fmt.Printf("created owner account %q\n", cfg.OwnerUsername)
+	}
+
+	if !*demo {
+		return
+	}
+
+	categoryRepo := repositories.NewCategoryRepository(db)
+	categoryService := services.NewCategoryService(categoryRepo)
+	productService := services.NewProductService(repositories.NewProductRepository(db), categoryRepo, nil)
+
+	existing, err := categoryService.GetAll(ctx)
+	if err != nil {
+		log.Fatal("Failed to read categories:", err)
+	}
+	if len(existing) > 0 {
+		log.Fatal("The catalog is not empty, refusing to load demo data")
+	}
+
+	for _, entry := range demoCatalog {
+		category, err := categoryService.Create(ctx, entry.category)
+		if err != nil {
+			log.Fatal("Failed to create category:", err)
+		}
+		for _, product := range entry.products {
+			product.CategoryID = category.ID
+			if _, err := productService.Create(ctx, product); err != nil {
+				log.Fatal("Failed to create product:", err)
+			}
+		}
+		fmt.Printf("created category %q with %d products\n", category.Name, len(entry.products))
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"flag"
+	"kasir-api/config"
+	"kasir-api/middleware"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"kasir-api/routes"
+	"kasir-api/services"
+	"log"
+	"net/http"
+	"os/signal"
+	"syscall"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// runServe handles `kasir-api serve`, the default command.
+func runServe(cfg *config.Config, args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	port := flags.String("port", cfg.Port, "port to listen on")
+	flags.Parse(args)
+	cfg.Port = *port
+
+	db := openDB(cfg)
+	defer db.Close()
+
+	if cfg.JWTSecret == "" {
+		log.Println("JWT_SECRET is not set, using a random secret; tokens will not survive a restart")
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			log.Fatal("Failed to generate JWT secret:", err)
+		}
+		cfg.JWTSecret = string(secret)
+	}
+
+	userService := services.NewUserService(repositories.NewUserRepository(db))
+	created, err := userService.EnsureOwner(context.Background(), cfg.OwnerUsername, cfg.OwnerPassword)
+	if err != nil {
+		log.Fatal("Failed to create owner account:", err)
+	}
+	if created {
+		log.Printf("Created owner account %q", cfg.OwnerUsername)
+	}
+
+	router := gin.Default()
+
+	if err := router.SetTrustedProxies([]string{"127.0.0.1"}); err != nil {
+		log.Fatal("Failed to set trusted proxies:", err)
+	}
+
+	router.Use(middleware.RequestID())
+	router.Use(middleware.Language())
+	router.Use(middleware.Errors())
+	router.Use(cors.New(cors.Config{
+		AllowOrigins:     []string{"*"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Accept-Language", "Authorization", "Idempotency-Key", "X-API-Key", "X-Request-ID"},
+		ExposeHeaders:    []string{"Content-Language", "Content-Length", "Idempotent-Replayed", "X-Request-ID"},
+		AllowCredentials: true,
+	}))
+
+	workers := routes.Routes(router, db, cfg)
+	router.NoRoute(func(c *gin.Context) {
+		c.Error(models.ErrRouteNotFound)
+	})
+
+	workers.Start()
+
+	srv := &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           router,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+
+	ctx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Printf("Kasir API server is running on port %s", cfg.Port)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Failed to start server:", err)
+		}
+	case <-ctx.Done():
+	}
+
+	// Stop accepting connections and let in-flight requests, such as a
+	// checkout in the middle of its transaction, finish before the
+	// database goes away.
+	log.Printf("Shutting down, waiting up to %s for requests to finish", cfg.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Requests still running after %s, closing connections: %v", cfg.ShutdownTimeout, err)
+		srv.Close()
+	}
+
+	// The print worker may be sending a job; the deferred db.Close runs
+	// only after it has finished.
+	workers.Stop()
+	log.Println("Server stopped")
+}
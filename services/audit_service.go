@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"time"
+)
+
+type AuditService struct {
+	auditRepo    *repositories.AuditRepository
+	writeTimeout time.Duration
+}
+
+// NewAuditService returns an AuditService whose writes give up after
+// writeTimeout.
+func NewAuditService(auditRepo *repositories.AuditRepository, writeTimeout time.Duration) *AuditService {
+	return &AuditService{auditRepo: auditRepo, writeTimeout: writeTimeout}
+}
+
+// Record appends a change to the audit log. before and after are stored as
+// JSON; nil leaves them empty. The change it describes has already been
+// saved, so the entry is written even if ctx is cancelled because the client
+// went away.
+func (s *AuditService) Record(ctx context.Context, entry models.AuditEntry, before, after interface{}) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.writeTimeout)
+	defer cancel()
+
+	var err error
+	if before != nil {
+		if entry.Before, err = json.Marshal(before); err != nil {
+			return err
+		}
+	}
+	if after != nil {
+		if entry.After, err = json.Marshal(after); err != nil {
+			return err
+		}
+	}
+	return s.auditRepo.Create(ctx, &entry)
+}
+
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 200
+)
+
+func (s *AuditService) GetAll(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, *models.Pagination, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 {
+		filter.Limit = defaultAuditPageSize
+	}
+	if filter.Limit > maxAuditPageSize {
+		filter.Limit = maxAuditPageSize
+	}
+
+	entries, total, err := s.auditRepo.GetAll(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return entries, &models.Pagination{
+		Page:  filter.Page,
+		Limit: filter.Limit,
+		Total: total,
+	}, nil
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"kasir-api/auth"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"time"
+)
+
+type AuthService struct {
+	userRepo   *repositories.UserRepository
+	tokens     *auth.TokenManager
+	refreshTTL time.Duration
+}
+
+func NewAuthService(userRepo *repositories.UserRepository, tokens *auth.TokenManager, refreshTTL time.Duration) *AuthService {
+	if refreshTTL <= 0 {
+		refreshTTL = 30 * 24 * time.Hour
+	}
+	return &AuthService{userRepo: userRepo, tokens: tokens, refreshTTL: refreshTTL}
+}
+
+func (s *AuthService) Login(ctx context.Context, req models.LoginRequest) (*models.TokenPair, error) {
+	user, err := s.userRepo.GetByUsername(ctx, req.Username)
+	if err == sql.ErrNoRows {
+		return nil, models.ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !auth.CheckPassword(user.PasswordHash, req.Password) || !user.Active {
+		return nil, models.ErrInvalidCredentials
+	}
+	return s.issue(ctx, user)
+}
+
+// Refresh exchanges a refresh token for a new token pair. The old refresh
+// token cannot be used again.
+func (s *AuthService) Refresh(ctx context.Context, req models.RefreshRequest) (*models.TokenPair, error) {
+	user, err := s.userRepo.UseRefreshToken(ctx, auth.HashToken(req.RefreshToken))
+	if err != nil {
+		return nil, err
+	}
+	return s.issue(ctx, user)
+}
+
+func (s *AuthService) Logout(ctx context.Context, req models.RefreshRequest) error {
+	return s.userRepo.RevokeRefreshToken(ctx, auth.HashToken(req.RefreshToken))
+}
+
+func (s *AuthService) issue(ctx context.Context, user *models.User) (*models.TokenPair, error) {
+	accessToken, expiresAt, err := s.tokens.Issue(user.ID, user.Username, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	refreshExpiresAt, err := s.userRepo.CreateRefreshToken(ctx, user.ID, auth.HashToken(refreshToken), s.refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TokenPair{
+		AccessToken:      accessToken,
+		TokenType:        "Bearer",
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
+		User:             user,
+	}, nil
+}
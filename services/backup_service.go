@@ -0,0 +1,32 @@
+package services
+
+import (
+	"context"
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+type BackupService struct {
+	backupRepo *repositories.BackupRepository
+}
+
+func NewBackupService(backupRepo *repositories.BackupRepository) *BackupService {
+	return &BackupService{backupRepo: backupRepo}
+}
+
+func (s *BackupService) Export(ctx context.Context) (*models.CatalogBackup, error) {
+	return s.backupRepo.Export(ctx)
+}
+
+// Import restores a backup after checking it. The stock of products that
+// already exist is only overwritten with overwriteStock, as it has usually
+// moved on since the export.
+func (s *BackupService) Import(ctx context.Context, backup *models.CatalogBackup, overwriteStock bool) (*models.ImportResult, error) {
+	if backup.Version != models.CatalogBackupVersion {
+		return nil, models.ErrUnsupportedBackup
+	}
+	if err := backup.Validate(); err != nil {
+		return nil, err
+	}
+	return s.backupRepo.Import(ctx, backup, overwriteStock)
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"kasir-api/models"
 	"kasir-api/repositories"
 	"strconv"
@@ -14,32 +15,41 @@ func NewCategoryService(repo *repositories.CategoryRepository) *CategoryService
 	return &CategoryService{repoCategory: repo}
 }
 
-func (s *CategoryService) GetAll() ([]models.Category, error) {
-	return s.repoCategory.GetAll()
+func (s *CategoryService) GetAll(ctx context.Context) ([]models.Category, error) {
+	return s.repoCategory.GetAll(ctx)
 }
 
-func (s *CategoryService) Create(data *models.Category) (*models.Category, error) {
-	if err := s.repoCategory.Create(data); err != nil {
+func (s *CategoryService) Create(ctx context.Context, req models.CategoryRequest) (*models.Category, error) {
+	category := newCategory(req)
+	if err := s.repoCategory.Create(ctx, category); err != nil {
 		return nil, err
 	}
-	return data, nil
+	return category, nil
 }
 
-func (s *CategoryService) GetByID(id int) (*models.Category, error) {
-	category, err := s.repoCategory.GetByID(strconv.Itoa(id))
+func (s *CategoryService) GetByID(ctx context.Context, id int) (*models.Category, error) {
+	category, err := s.repoCategory.GetByID(ctx, strconv.Itoa(id))
 	if err != nil {
-		return nil, err
+		return nil, notFound(err, models.ErrCategoryNotFound)
 	}
 	return category, nil
 }
 
-func (s *CategoryService) Update(id int, data *models.Category) (*models.Category, error) {
-	if err := s.repoCategory.Update(strconv.Itoa(id), data); err != nil {
+func (s *CategoryService) Update(ctx context.Context, id int, req models.CategoryRequest) (*models.Category, error) {
+	if err := s.repoCategory.Update(ctx, strconv.Itoa(id), newCategory(req)); err != nil {
 		return nil, err
 	}
-	return s.GetByID(id)
+	return s.GetByID(ctx, id)
 }
 
-func (s *CategoryService) Delete(id int) error {
-	return s.repoCategory.Delete(strconv.Itoa(id))
+func (s *CategoryService) Delete(ctx context.Context, id int) error {
+	return notFound(s.repoCategory.Delete(ctx, strconv.Itoa(id)), models.ErrCategoryNotFound)
+}
+
+func newCategory(req models.CategoryRequest) *models.Category {
+	return &models.Category{
+		Name:        req.Name,
+		Description: req.Description,
+		TaxExempt:   req.TaxExempt,
+	}
 }
@@ -0,0 +1,15 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// notFound replaces sql.ErrNoRows with notFoundErr, the not found error of
+// the entity being looked up, and passes any other error through.
+func notFound(err, notFoundErr error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFoundErr
+	}
+	return err
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"kasir-api/repositories"
+	"log"
+	"sync"
+	"time"
+)
+
+// IdempotencyKeyCleaner deletes checkout idempotency keys past their
+// retention window from a background worker. Lookups already ignore such
+// keys, so this only keeps the table from growing; doing it here keeps the
+// delete out of checkout.
+type IdempotencyKeyCleaner struct {
+	transactionRepo *repositories.TransactionRepository
+	ttl             time.Duration
+	interval        time.Duration
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewIdempotencyKeyCleaner(transactionRepo *repositories.TransactionRepository, ttl, interval time.Duration) *IdempotencyKeyCleaner {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &IdempotencyKeyCleaner{
+		transactionRepo: transactionRepo,
+		ttl:             ttl,
+		interval:        interval,
+		stop:            make(chan struct{}),
+	}
+}
+
+// Start launches the worker, which cleans up at once and then every
+// interval.
+func (c *IdempotencyKeyCleaner) Start() {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			if err := c.transactionRepo.DeleteExpiredIdempotencyKeys(context.Background(), c.ttl); err != nil {
+				log.Printf("Failed to delete expired idempotency keys: %v", err)
+			}
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop waits for a running cleanup, if any, and stops the worker.
+func (c *IdempotencyKeyCleaner) Stop() {
+	close(c.stop)
+	c.wg.Wait()
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"kasir-api/models"
+	"kasir-api/printer"
+	"kasir-api/receipt"
+	"kasir-api/repositories"
+	"log"
+	"sync"
+	"time"
+)
+
+type PrintConfig struct {
+	// DefaultAddress is the printer used when a request names none; empty
+	// means printing always needs an explicit address.
+	DefaultAddress string
+	// Allowed are the other printers a request may name.
+	Allowed []string
+	// AutoPrint queues a receipt on DefaultAddress after every checkout.
+	AutoPrint     bool
+	MaxAttempts   int
+	RetryInterval time.Duration
+	Timeout       time.Duration
+	// QueryTimeout bounds queueing the automatic receipt after checkout.
+	QueryTimeout time.Duration
+	Paper        int
+	Store        receipt.Store
+}
+
+// PrintService queues receipts for network printers and sends them from a
+// background worker, retrying failed sends with a growing delay.
+type PrintService struct {
+	jobRepo         *repositories.PrintJobRepository
+	transactionRepo *repositories.TransactionRepository
+	cfg             PrintConfig
+
+	wake chan struct{}
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewPrintService(jobRepo *repositories.PrintJobRepository, transactionRepo *repositories.TransactionRepository, cfg PrintConfig) *PrintService {
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 1
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = 5 * time.Second
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	if !receipt.IsValidPaper(cfg.Paper) {
+		cfg.Paper = receipt.Paper58mm
+	}
+	return &PrintService{
+		jobRepo:         jobRepo,
+		transactionRepo: transactionRepo,
+		cfg:             cfg,
+		wake:            make(chan struct{}, 1),
+		stop:            make(chan struct{}),
+	}
+}
+
+// Print queues the receipt of a transaction for printing.
+func (s *PrintService) Print(ctx context.Context, transactionID int, req models.PrintRequest) (*models.PrintJob, error) {
+	if _, err := s.transactionRepo.GetTransactionByID(ctx, transactionID); err != nil {
+		return nil, notFound(err, models.ErrTransactionNotFound)
+	}
+
+	job := &models.PrintJob{
+		TransactionID:  transactionID,
+		PrinterAddress: req.PrinterAddress,
+		PaperWidth:     req.PaperWidth,
+	}
+	if job.PrinterAddress == "" {
+		job.PrinterAddress = s.cfg.DefaultAddress
+	}
+	if job.PrinterAddress == "" {
+		return nil, models.ErrNoPrinter
+	}
+	if !s.isAllowed(job.PrinterAddress) {
+		return nil, models.ErrPrinterNotAllowed
+	}
+	if job.PaperWidth == 0 {
+		job.PaperWidth = s.cfg.Paper
+	}
+
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+	s.notify()
+	return job, nil
+}
+
+// isAllowed reports whether address is a configured printer. Requests may
+// not make the server connect anywhere else.
+func (s *PrintService) isAllowed(address string) bool {
+	if address == s.cfg.DefaultAddress {
+		return true
+	}
+	for _, allowed := range s.cfg.Allowed {
+		if address == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// AfterCheckout queues the receipt on the default printer when auto print is
+// on. The sale has already succeeded, so failures are only logged.
+func (s *PrintService) AfterCheckout(ctx context.Context, transaction *models.Transaction) {
+	if !s.cfg.AutoPrint || s.cfg.DefaultAddress == "" {
+		return
+	}
+	// The sale is committed even if the client has gone away, so the
+	// receipt is queued regardless.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.cfg.QueryTimeout)
+	defer cancel()
+	if _, err := s.Print(ctx, transaction.ID, models.PrintRequest{}); err != nil {
+		log.Printf("Failed to queue receipt for transaction %d: %v", transaction.ID, err)
+	}
+}
+
+func (s *PrintService) GetJob(ctx context.Context, id int) (*models.PrintJob, error) {
+	job, err := s.jobRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, notFound(err, models.ErrPrintJobNotFound)
+	}
+	return job, nil
+}
+
+func (s *PrintService) GetJobsByTransactionID(ctx context.Context, transactionID int) ([]models.PrintJob, error) {
+	return s.jobRepo.GetByTransactionID(ctx, transactionID)
+}
+
+// Start launches the worker. Jobs are picked up when queued and every
+// retry interval after that. The worker does not belong to any request, so
+// its queries run until done; Stop waits for them.
+func (s *PrintService) Start() {
+	ctx := context.Background()
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.cfg.RetryInterval)
+		defer ticker.Stop()
+		for {
+			s.drain(ctx)
+			select {
+			case <-s.stop:
+				return
+			case <-s.wake:
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop waits for the job being sent, if any, and stops the worker.
+func (s *PrintService) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+func (s *PrintService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// lease is how long a claimed job stays with its worker: long enough to
+// build the receipt and send it within the printer timeout.
+func (s *PrintService) lease() time.Duration {
+	return 2*s.cfg.Timeout + 30*time.Second
+}
+
+func (s *PrintService) drain(ctx context.Context) {
+	if err := s.jobRepo.RequeueExpired(ctx); err != nil {
+		log.Printf("Failed to requeue interrupted print jobs: %v", err)
+	}
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+
+		job, err := s.jobRepo.ClaimDue(ctx, s.lease())
+		if err == sql.ErrNoRows {
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to fetch print jobs: %v", err)
+			return
+		}
+		s.process(ctx, job)
+	}
+}
+
+func (s *PrintService) process(ctx context.Context, job *models.PrintJob) {
+	// Jobs queued before the address was restricted are not sent.
+	if !s.isAllowed(job.PrinterAddress) {
+		if err := s.jobRepo.MarkFailed(ctx, job.ID, "printer address is not allowed"); err != nil {
+			log.Printf("Failed to update print job %d: %v", job.ID, err)
+		}
+		return
+	}
+
+	err := s.send(ctx, job)
+	if err == nil {
+		err = s.jobRepo.MarkDone(ctx, job.ID)
+		if err != nil {
+			log.Printf("Failed to mark print job %d done: %v", job.ID, err)
+		}
+		return
+	}
+
+	if job.Attempts >= s.cfg.MaxAttempts {
+		log.Printf("Print job %d failed after %d attempts: %v", job.ID, job.Attempts, err)
+		err = s.jobRepo.MarkFailed(ctx, job.ID, err.Error())
+	} else {
+		err = s.jobRepo.MarkRetry(ctx, job.ID, err.Error(), s.cfg.RetryInterval*time.Duration(job.Attempts))
+	}
+	if err != nil {
+		log.Printf("Failed to update print job %d: %v", job.ID, err)
+	}
+}
+
+func (s *PrintService) send(ctx context.Context, job *models.PrintJob) error {
+	transaction, err := s.transactionRepo.GetTransactionByID(ctx, job.TransactionID)
+	if err != nil {
+		return err
+	}
+	data := receipt.Build(s.cfg.Store, transaction, job.PaperWidth).ESCPOS()
+	return printer.Send(job.PrinterAddress, data, s.cfg.Timeout)
+}
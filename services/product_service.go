@@ -1,45 +1,183 @@
 package services
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"kasir-api/barcode"
 	"kasir-api/models"
 	"kasir-api/repositories"
 	"strconv"
 )
 
 type ProductService struct {
-	productRepo *repositories.ProductRepository
+	productRepo  *repositories.ProductRepository
+	categoryRepo *repositories.CategoryRepository
+	scaleFormats []barcode.Format
 }
 
-func NewProductService(productRepo *repositories.ProductRepository) *ProductService {
-	return &ProductService{productRepo: productRepo}
+// NewProductService returns a ProductService. scaleFormats are the scale
+// label layouts the barcode lookup understands; none disables them.
+func NewProductService(productRepo *repositories.ProductRepository, categoryRepo *repositories.CategoryRepository, scaleFormats []barcode.Format) *ProductService {
+	return &ProductService{productRepo: productRepo, categoryRepo: categoryRepo, scaleFormats: scaleFormats}
 }
 
-func (s *ProductService) GetAll(name string) ([]models.Product, error) {
-	return s.productRepo.GetAll(name)
+const (
+	defaultProductPageSize = 50
+	maxProductPageSize     = 200
+)
+
+// GetAll returns a page of products. With a cursor from a previous page it
+// continues after that page, in the sort order the cursor was made for.
+func (s *ProductService) GetAll(ctx context.Context, filter models.ProductFilter, cursor string) ([]models.Product, *models.ProductPagination, error) {
+	if filter.Limit < 1 {
+		filter.Limit = defaultProductPageSize
+	}
+	if filter.Limit > maxProductPageSize {
+		filter.Limit = maxProductPageSize
+	}
+
+	if cursor != "" {
+		after, err := models.DecodeProductCursor(cursor)
+		if err != nil || (filter.Sort != "" && filter.Sort != after.Sort) || (filter.Order != "" && filter.Order != after.Order) {
+			return nil, nil, models.NewInvalidFieldError("cursor", "invalid", "")
+		}
+		filter.After = after
+		filter.Sort, filter.Order, filter.Offset = after.Sort, after.Order, 0
+	}
+	if filter.Sort == "" {
+		filter.Sort = models.ProductSortName
+	}
+	if filter.Order == "" {
+		filter.Order = models.SortAsc
+	}
+
+	// Ask for one product more than the page holds to learn whether there
+	// is a next page.
+	limit := filter.Limit
+	filter.Limit++
+	products, total, err := s.productRepo.GetAll(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pagination := &models.ProductPagination{Limit: limit, Offset: filter.Offset, Total: total}
+	if len(products) > limit {
+		products = products[:limit]
+		pagination.NextCursor = models.NewProductCursor(filter.Sort, filter.Order, products[limit-1]).Encode()
+	}
+	return products, pagination, nil
 }
 
-func (s *ProductService) Create(data *models.Product) (*models.Product, error) {
-	if err := s.productRepo.Create(data); err != nil {
+func (s *ProductService) Create(ctx context.Context, req models.ProductRequest) (*models.Product, error) {
+	if err := s.checkCategory(ctx, req.CategoryID); err != nil {
+		return nil, err
+	}
+	product := newProduct(req)
+	if err := s.productRepo.Create(ctx, product); err != nil {
 		return nil, err
 	}
-	return data, nil
+	return product, nil
 }
 
-func (s *ProductService) GetByID(id int) (*models.Product, error) {
-	product, err := s.productRepo.GetByID(strconv.Itoa(id))
+func (s *ProductService) GetByID(ctx context.Context, id int) (*models.Product, error) {
+	product, err := s.productRepo.GetByID(ctx, strconv.Itoa(id))
 	if err != nil {
-		return nil, err
+		return nil, notFound(err, models.ErrProductNotFound)
 	}
 	return product, nil
 }
 
-func (s *ProductService) Update(id int, data *models.Product) (*models.Product, error) {
-	if err := s.productRepo.Update(strconv.Itoa(id), data); err != nil {
+// GetByBarcode returns the product a scanned barcode belongs to. A barcode
+// that no product carries is read as a scale label, whose PLU names the
+// product and whose price, weight or count is returned along with it.
+func (s *ProductService) GetByBarcode(ctx context.Context, code string) (*models.ScannedProduct, error) {
+	product, err := s.productRepo.GetByBarcode(ctx, code)
+	if err == nil {
+		return &models.ScannedProduct{Product: *product}, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	label, err := barcode.Parse(s.scaleFormats, code)
+	if err != nil {
+		return nil, models.NewInvalidBarcodeError(code)
+	}
+	if label == nil {
+		return nil, models.ErrProductNotFound
+	}
+	product, err = s.productRepo.GetByPLU(ctx, label.PLU)
+	if err != nil {
+		return nil, notFound(err, models.ErrProductNotFound)
+	}
+
+	scale := &models.ScaleLabel{
+		PLU:       label.PLU,
+		Quantity:  label.Quantity(),
+		UnitPrice: label.UnitPrice(product.Price),
+	}
+	if label.Kind == barcode.Weight {
+		scale.WeightGrams = label.Value
+	}
+	scale.Subtotal = scale.Quantity * scale.UnitPrice
+	return &models.ScannedProduct{Product: *product, Scale: scale}, nil
+}
+
+func (s *ProductService) Update(ctx context.Context, id int, req models.ProductRequest) (*models.Product, error) {
+	current, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkCategory(ctx, req.CategoryID); err != nil {
 		return nil, err
 	}
-	return s.GetByID(id)
+
+	product := newProduct(req)
+	if req.SKU == nil {
+		product.SKU = current.SKU
+	}
+	if req.Barcodes == nil {
+		product.Barcodes = current.Barcodes
+	}
+	if req.PLU == nil {
+		product.PLU = current.PLU
+	}
+	if err := s.productRepo.Update(ctx, strconv.Itoa(id), product); err != nil {
+		return nil, notFound(err, models.ErrProductNotFound)
+	}
+	return s.GetByID(ctx, id)
+}
+
+func (s *ProductService) Delete(ctx context.Context, id int) error {
+	return notFound(s.productRepo.Delete(ctx, strconv.Itoa(id)), models.ErrProductNotFound)
+}
+
+// checkCategory fails with a field error when the category does not exist.
+// The foreign key still guards against a category deleted in between.
+func (s *ProductService) checkCategory(ctx context.Context, categoryID int) error {
+	_, err := s.categoryRepo.GetByID(ctx, strconv.Itoa(categoryID))
+	return notFound(err, models.ErrProductCategoryNotFound)
 }
 
-func (s *ProductService) Delete(id int) error {
-	return s.productRepo.Delete(strconv.Itoa(id))
-}
\ No newline at end of file
+func newProduct(req models.ProductRequest) *models.Product {
+	product := &models.Product{
+		CategoryID: req.CategoryID,
+		Barcodes:   req.Barcodes,
+		Name:       req.Name,
+		Price:      req.Price,
+		Cost:       req.Cost,
+		Stock:      req.Stock,
+		TaxExempt:  req.TaxExempt,
+	}
+	if req.SKU != nil {
+		product.SKU = *req.SKU
+	}
+	if req.PLU != nil {
+		product.PLU = *req.PLU
+	}
+	if product.Barcodes == nil {
+		product.Barcodes = make([]string, 0)
+	}
+	return product
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"strconv"
+)
+
+type PromotionService struct {
+	promotionRepo *repositories.PromotionRepository
+}
+
+func NewPromotionService(promotionRepo *repositories.PromotionRepository) *PromotionService {
+	return &PromotionService{promotionRepo: promotionRepo}
+}
+
+func (s *PromotionService) GetAll(ctx context.Context) ([]models.Promotion, error) {
+	return s.promotionRepo.GetAll(ctx)
+}
+
+func (s *PromotionService) Create(ctx context.Context, data *models.Promotion) (*models.Promotion, error) {
+	if err := s.promotionRepo.Create(ctx, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (s *PromotionService) GetByID(ctx context.Context, id int) (*models.Promotion, error) {
+	promotion, err := s.promotionRepo.GetByID(ctx, strconv.Itoa(id))
+	if err != nil {
+		return nil, notFound(err, models.ErrPromotionNotFound)
+	}
+	return promotion, nil
+}
+
+func (s *PromotionService) Update(ctx context.Context, id int, data *models.Promotion) (*models.Promotion, error) {
+	if err := s.promotionRepo.Update(ctx, strconv.Itoa(id), data); err != nil {
+		return nil, notFound(err, models.ErrPromotionNotFound)
+	}
+	return s.GetByID(ctx, id)
+}
+
+func (s *PromotionService) Delete(ctx context.Context, id int) error {
+	return notFound(s.promotionRepo.Delete(ctx, strconv.Itoa(id)), models.ErrPromotionNotFound)
+}
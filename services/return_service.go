@@ -0,0 +1,27 @@
+package services
+
+import (
+	"context"
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+type ReturnService struct {
+	returnRepo *repositories.ReturnRepository
+}
+
+func NewReturnService(returnRepo *repositories.ReturnRepository) *ReturnService {
+	return &ReturnService{returnRepo: returnRepo}
+}
+
+func (s *ReturnService) Create(ctx context.Context, transactionID int, req models.ReturnRequest) (*models.Return, error) {
+	ret, err := s.returnRepo.Create(ctx, transactionID, req)
+	if err != nil {
+		return nil, notFound(err, models.ErrTransactionNotFound)
+	}
+	return ret, nil
+}
+
+func (s *ReturnService) GetByTransactionID(ctx context.Context, transactionID int) ([]models.Return, error) {
+	return s.returnRepo.GetByTransactionID(ctx, transactionID)
+}
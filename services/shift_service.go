@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+type ShiftService struct {
+	shiftRepo *repositories.ShiftRepository
+}
+
+func NewShiftService(shiftRepo *repositories.ShiftRepository) *ShiftService {
+	return &ShiftService{shiftRepo: shiftRepo}
+}
+
+func (s *ShiftService) Open(ctx context.Context, req models.OpenShiftRequest) (*models.Shift, error) {
+	return s.shiftRepo.Open(ctx, req)
+}
+
+func (s *ShiftService) GetAll(ctx context.Context, status string) ([]models.Shift, error) {
+	return s.shiftRepo.GetAll(ctx, status)
+}
+
+func (s *ShiftService) GetByID(ctx context.Context, id int) (*models.Shift, error) {
+	shift, err := s.shiftRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, notFound(err, models.ErrShiftNotFound)
+	}
+	return shift, nil
+}
+
+func (s *ShiftService) AddCashMovement(ctx context.Context, shiftID int, m *models.CashMovement) (*models.CashMovement, error) {
+	m.ShiftID = shiftID
+	if err := s.shiftRepo.AddCashMovement(ctx, m); err != nil {
+		return nil, notFound(err, models.ErrShiftNotFound)
+	}
+	return m, nil
+}
+
+func (s *ShiftService) Close(ctx context.Context, id int, req models.CloseShiftRequest) (*models.ShiftSummary, error) {
+	summary, err := s.shiftRepo.Close(ctx, id, req)
+	if err != nil {
+		return nil, notFound(err, models.ErrShiftNotFound)
+	}
+	return summary, nil
+}
+
+func (s *ShiftService) GetSummary(ctx context.Context, id int) (*models.ShiftSummary, error) {
+	summary, err := s.shiftRepo.GetSummary(ctx, id)
+	if err != nil {
+		return nil, notFound(err, models.ErrShiftNotFound)
+	}
+	return summary, nil
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"kasir-api/auth"
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+// apiKeyPrefix marks terminal keys so they are easy to tell apart from
+// access tokens and to find in leaked text.
+const apiKeyPrefix = "kt_"
+
+type TerminalService struct {
+	terminalRepo *repositories.TerminalRepository
+}
+
+func NewTerminalService(terminalRepo *repositories.TerminalRepository) *TerminalService {
+	return &TerminalService{terminalRepo: terminalRepo}
+}
+
+func (s *TerminalService) GetAll(ctx context.Context) ([]models.Terminal, error) {
+	return s.terminalRepo.GetAll(ctx)
+}
+
+func (s *TerminalService) GetByID(ctx context.Context, id int) (*models.Terminal, error) {
+	terminal, err := s.terminalRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, notFound(err, models.ErrTerminalNotFound)
+	}
+	return terminal, nil
+}
+
+func (s *TerminalService) Create(ctx context.Context, req models.TerminalRequest) (*models.Terminal, error) {
+	t := &models.Terminal{
+		Name:        req.Name,
+		Permissions: req.Permissions,
+		Active:      true,
+	}
+	if t.Permissions == nil {
+		t.Permissions = models.DefaultTerminalPermissions()
+	}
+	if req.Active != nil {
+		t.Active = *req.Active
+	}
+	if err := s.terminalRepo.Create(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (s *TerminalService) Update(ctx context.Context, id int, req models.TerminalRequest) (*models.Terminal, error) {
+	t, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	t.Name = req.Name
+	if req.Permissions != nil {
+		t.Permissions = req.Permissions
+	}
+	if req.Active != nil {
+		t.Active = *req.Active
+	}
+	if err := s.terminalRepo.Update(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// IssueAPIKey creates a new key for the terminal. The key is only returned
+// here; the database keeps its hash.
+func (s *TerminalService) IssueAPIKey(ctx context.Context, terminalID int) (*models.IssuedAPIKey, error) {
+	if _, err := s.GetByID(ctx, terminalID); err != nil {
+		return nil, err
+	}
+
+	token, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	key := apiKeyPrefix + token
+
+	stored, err := s.terminalRepo.CreateAPIKey(ctx, terminalID, key[:len(apiKeyPrefix)+8], auth.HashToken(key))
+	if err != nil {
+		return nil, err
+	}
+	return &models.IssuedAPIKey{TerminalAPIKey: *stored, Key: key}, nil
+}
+
+func (s *TerminalService) GetAPIKeys(ctx context.Context, terminalID int) ([]models.TerminalAPIKey, error) {
+	if _, err := s.GetByID(ctx, terminalID); err != nil {
+		return nil, err
+	}
+	return s.terminalRepo.GetAPIKeys(ctx, terminalID)
+}
+
+func (s *TerminalService) RevokeAPIKey(ctx context.Context, terminalID, keyID int) (*models.TerminalAPIKey, error) {
+	key, err := s.terminalRepo.RevokeAPIKey(ctx, terminalID, keyID)
+	if err != nil {
+		return nil, notFound(err, models.ErrAPIKeyNotFound)
+	}
+	return key, nil
+}
+
+// AuthenticateKey returns the terminal an API key belongs to.
+func (s *TerminalService) AuthenticateKey(ctx context.Context, key string) (*models.Terminal, error) {
+	return s.terminalRepo.GetByAPIKey(ctx, auth.HashToken(key))
+}
@@ -1,26 +1,148 @@
 package services
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"kasir-api/barcode"
 	"kasir-api/models"
+	"kasir-api/pricing"
 	"kasir-api/repositories"
+	"time"
 )
 
 type TransactionService struct {
 	transactionRepo *repositories.TransactionRepository
+	promotionRepo   *repositories.PromotionRepository
+	tax             pricing.TaxConfig
+	scaleFormats    []barcode.Format
+	// keyTTL is how long an idempotency key is replayed.
+	keyTTL time.Duration
 }
 
-func NewTransactionService(transactionRepo *repositories.TransactionRepository) *TransactionService {
-	return &TransactionService{transactionRepo: transactionRepo}
+func NewTransactionService(transactionRepo *repositories.TransactionRepository, promotionRepo *repositories.PromotionRepository, tax pricing.TaxConfig, scaleFormats []barcode.Format, keyTTL time.Duration) *TransactionService {
+	return &TransactionService{transactionRepo: transactionRepo, promotionRepo: promotionRepo, tax: tax, scaleFormats: scaleFormats, keyTTL: keyTTL}
 }
 
-func (s *TransactionService) Checkout(items []models.CheckoutItem, useLock bool) (*models.Transaction, error) {
-	return s.transactionRepo.CreateTransaction(items)
+// Checkout creates a transaction for the requested items and payments, with
+// the currently active promotions, tax and service charge applied. When key is set, a
+// previous checkout by the same caller with the same key, within the
+// retention window, is replayed instead of charging again; the returned
+// bool reports whether that happened. terminalID is the
+// terminal the sale was rung up on, or 0 when a user checked out directly.
+func (s *TransactionService) Checkout(ctx context.Context, req models.CheckoutRequest, useLock bool, key *models.IdempotencyKey, terminalID int) (*models.Transaction, bool, error) {
+	if key != nil {
+		existing, err := s.transactionRepo.GetIdempotencyKey(ctx, key.Scope, key.Key, s.keyTTL)
+		if err == nil {
+			return s.replay(existing, key)
+		}
+		if err != sql.ErrNoRows {
+			return nil, false, err
+		}
+	}
+
+	promotions, err := s.promotionRepo.GetActive(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	transaction, err := s.transactionRepo.CreateTransaction(ctx, req, repositories.CheckoutOptions{
+		Promotions:     promotions,
+		Tax:            s.tax,
+		ScaleFormats:   s.scaleFormats,
+		UseLock:        useLock,
+		IdempotencyKey: key,
+		KeyTTL:         s.keyTTL,
+		TerminalID:     terminalID,
+	})
+	if errors.Is(err, models.ErrIdempotencyKeyInProgress) {
+		// Another request with the same key won the race; it has committed
+		// or rolled back by the time the claim above gave up.
+		existing, lookupErr := s.transactionRepo.GetIdempotencyKey(ctx, key.Scope, key.Key, s.keyTTL)
+		if lookupErr != nil {
+			return nil, false, err
+		}
+		return s.replay(existing, key)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return transaction, false, nil
+}
+
+func (s *TransactionService) replay(existing, key *models.IdempotencyKey) (*models.Transaction, bool, error) {
+	if existing.RequestHash != key.RequestHash {
+		return nil, false, models.ErrIdempotencyKeyMismatch
+	}
+	if existing.Response == nil {
+		return nil, false, models.ErrIdempotencyKeyInProgress
+	}
+	var transaction models.Transaction
+	if err := json.Unmarshal(existing.Response, &transaction); err != nil {
+		return nil, false, err
+	}
+	return &transaction, true, nil
+}
+
+const (
+	defaultTransactionPageSize = 20
+	maxTransactionPageSize     = 100
+)
+
+func (s *TransactionService) GetAll(ctx context.Context, filter models.TransactionFilter) ([]models.Transaction, *models.Pagination, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 {
+		filter.Limit = defaultTransactionPageSize
+	}
+	if filter.Limit > maxTransactionPageSize {
+		filter.Limit = maxTransactionPageSize
+	}
+
+	transactions, total, err := s.transactionRepo.GetTransactions(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return transactions, &models.Pagination{
+		Page:  filter.Page,
+		Limit: filter.Limit,
+		Total: total,
+	}, nil
+}
+
+func (s *TransactionService) GetByID(ctx context.Context, id int) (*models.Transaction, error) {
+	transaction, err := s.transactionRepo.GetTransactionByID(ctx, id)
+	if err != nil {
+		return nil, notFound(err, models.ErrTransactionNotFound)
+	}
+	return transaction, nil
+}
+
+func (s *TransactionService) Void(ctx context.Context, id int, req models.CancelTransactionRequest) (*models.Transaction, error) {
+	return s.cancel(ctx, id, models.TransactionStatusVoided, req)
+}
+
+func (s *TransactionService) Refund(ctx context.Context, id int, req models.CancelTransactionRequest) (*models.Transaction, error) {
+	return s.cancel(ctx, id, models.TransactionStatusRefunded, req)
+}
+
+func (s *TransactionService) cancel(ctx context.Context, id int, status string, req models.CancelTransactionRequest) (*models.Transaction, error) {
+	if err := s.transactionRepo.CancelTransaction(ctx, id, status, req); err != nil {
+		return nil, notFound(err, models.ErrTransactionNotFound)
+	}
+	return s.GetByID(ctx, id)
+}
+
+func (s *TransactionService) GetReport(ctx context.Context) (*models.TransactionReport, error) {
+	return s.transactionRepo.GetReport(ctx)
 }
 
-func (s *TransactionService) GetReport() (*models.TransactionReport, error) {
-	return s.transactionRepo.GetReport()
+func (s *TransactionService) GetReportByDateRange(ctx context.Context, startDate, endDate string) (*models.TransactionReport, error) {
+	return s.transactionRepo.GetReportByDateRange(ctx, startDate, endDate)
 }
 
-func (s *TransactionService) GetReportByDateRange(startDate, endDate string) (*models.TransactionReport, error) {
-	return s.transactionRepo.GetReportByDateRange(startDate, endDate)
+func (s *TransactionService) GetTaxSummary(ctx context.Context, startDate, endDate string) (*models.TaxSummary, error) {
+	return s.transactionRepo.GetTaxSummary(ctx, startDate, endDate)
 }
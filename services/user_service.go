@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"kasir-api/auth"
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+type UserService struct {
+	userRepo *repositories.UserRepository
+}
+
+func NewUserService(userRepo *repositories.UserRepository) *UserService {
+	return &UserService{userRepo: userRepo}
+}
+
+func (s *UserService) GetAll(ctx context.Context) ([]models.User, error) {
+	return s.userRepo.GetAll(ctx)
+}
+
+func (s *UserService) GetByID(ctx context.Context, id int) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, notFound(err, models.ErrUserNotFound)
+	}
+	return user, nil
+}
+
+func (s *UserService) Create(ctx context.Context, req models.CreateUserRequest) (*models.User, error) {
+	hash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+	user := &models.User{
+		Username:     req.Username,
+		PasswordHash: hash,
+		Role:         req.Role,
+		Active:       true,
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *UserService) Update(ctx context.Context, id int, req models.UpdateUserRequest) (*models.User, error) {
+	user, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Password != nil {
+		user.PasswordHash, err = auth.HashPassword(*req.Password)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if req.Role != nil {
+		user.Role = *req.Role
+	}
+	if req.Active != nil {
+		user.Active = *req.Active
+	}
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// AuthenticateUser returns the user an access token was issued to, as long
+// as they still exist and are active.
+func (s *UserService) AuthenticateUser(ctx context.Context, id int) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err == sql.ErrNoRows || (err == nil && !user.Active) {
+		return nil, models.ErrInvalidAccessToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// EnsureOwner creates the first owner account when there are no users yet,
+// so a fresh install can log in. It does nothing once any user exists.
+func (s *UserService) EnsureOwner(ctx context.Context, username, password string) (bool, error) {
+	if username == "" || password == "" {
+		return false, nil
+	}
+	count, err := s.userRepo.Count(ctx)
+	if err != nil || count > 0 {
+		return false, err
+	}
+	_, err = s.Create(ctx, models.CreateUserRequest{
+		Username: username,
+		Password: password,
+		Role:     models.RoleOwner,
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"kasir-api/config"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"kasir-api/services"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// minPasswordLength matches the check done by the users API.
+const minPasswordLength = 8
+
+// runUser handles `kasir-api user create|list`.
+func runUser(cfg *config.Config, args []string) {
+	if len(args) == 0 {
+		log.Fatal("usage: kasir-api user create --username NAME --role ROLE | list")
+	}
+
+	switch args[0] {
+	case "create":
+		createUser(cfg, args[1:])
+	case "list":
+		listUsers(cfg)
+	default:
+		log.Fatal("usage: kasir-api user create --username NAME --role ROLE | list")
+	}
+}
+
+func createUser(cfg *config.Config, args []string) {
+	flags := flag.NewFlagSet("user create", flag.ExitOnError)
+	username := flags.String("username", "", "login name")
+	role := flags.String("role", models.RoleCashier, "owner, admin or cashier")
+	flags.Parse(args)
+
+	if *username == "" {
+		log.Fatal("--username is required")
+	}
+	if strings.Contains(*username, ":") {
+		log.Fatal("--username must not contain ':'")
+	}
+	if !models.IsValidRole(*role) {
+		log.Fatal("--role must be owner, admin or cashier")
+	}
+
+	// The password is read from stdin rather than a flag so it does not end
+	// up in the shell history or the process list.
+	fmt.Fprint(os.Stderr, "Password: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		log.Fatal("Failed to read password:", err)
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if len(password) < minPasswordLength {
+		log.Fatalf("Password must be at least %d characters", minPasswordLength)
+	}
+
+	db := openDB(cfg)
+	defer db.Close()
+
+	userService := services.NewUserService(repositories.NewUserRepository(db))
+	user, err := userService.Create(context.Background(), models.CreateUserRequest{
+		Username: *username,
+		Password: password,
+		Role:     *role,
+	})
+	if errors.Is(err, models.ErrUsernameTaken) {
+		log.Fatalf("Username %q already exists", *username)
+	}
+	if err != nil {
+		log.Fatal("Failed to create user:", err)
+	}
+	fmt.Printf("created %s %q with id %d\n", user.Role, user.Username, user.ID)
+}
+
+func listUsers(cfg *config.Config) {
+	db := openDB(cfg)
+	defer db.Close()
+
+	users, err := services.NewUserService(repositories.NewUserRepository(db)).GetAll(context.Background())
+	if err != nil {
+		log.Fatal("Failed to read users:", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tUSERNAME\tROLE\tACTIVE")
+	for _, u := range users {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%t\n", u.ID, u.Username, u.Role, u.Active)
+	}
+	w.Flush()
+}
@@ -0,0 +1,95 @@
+// Package validation checks request DTOs against the rules declared in their
+// `validate` struct tags and reports every invalid field at once.
+package validation
+
+import (
+	"errors"
+	"kasir-api/models"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	// Report fields by their JSON name, which is what clients send.
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	v.RegisterValidation("terminal_permission", func(fl validator.FieldLevel) bool {
+		return models.IsGrantableToTerminal(fl.Field().String())
+	})
+	v.RegisterStructValidation(checkoutItem, models.CheckoutItem{})
+	return v
+}
+
+// checkoutItem requires exactly one of product_id and barcode.
+func checkoutItem(sl validator.StructLevel) {
+	item := sl.Current().Interface().(models.CheckoutItem)
+	switch {
+	case item.ProductID == 0 && item.Barcode == "":
+		sl.ReportError(item.ProductID, "product_id", "ProductID", "required_without", "barcode")
+	case item.ProductID != 0 && item.Barcode != "":
+		sl.ReportError(item.ProductID, "product_id", "ProductID", "excluded_with", "barcode")
+	}
+}
+
+// Struct validates req and returns a validation error listing every field
+// that breaks its rules, or nil when req is valid.
+func Struct(req interface{}) error {
+	err := validate.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return err
+	}
+
+	fields := make([]models.FieldError, 0, len(fieldErrs))
+	for _, fe := range fieldErrs {
+		fields = append(fields, models.NewFieldError(fieldName(fe), rule(fe), fe.Param()))
+	}
+	return models.NewFieldErrors(fields...)
+}
+
+// fieldName is the path of the field without the struct name, such as
+// "name" or "items[0].quantity".
+func fieldName(fe validator.FieldError) string {
+	_, name, ok := strings.Cut(fe.Namespace(), ".")
+	if !ok {
+		return fe.Field()
+	}
+	return name
+}
+
+// rule names the broken rule. Length rules on strings are reported as min
+// and max, so their message speaks of characters, and min and max on a list
+// as min_items and max_items.
+func rule(fe validator.FieldError) string {
+	switch fe.Kind() {
+	case reflect.String:
+		switch fe.Tag() {
+		case "gte":
+			return "min"
+		case "lte":
+			return "max"
+		}
+	case reflect.Slice:
+		switch fe.Tag() {
+		case "min":
+			return "min_items"
+		case "max":
+			return "max_items"
+		}
+	}
+	return fe.Tag()
+}